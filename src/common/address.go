@@ -0,0 +1,155 @@
+package common
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// MainAddressPrefix - human-readable prefix of main network addresses
+	MainAddressPrefix = "indo"
+
+	// TestAddressPrefix - human-readable prefix of test network addresses
+	TestAddressPrefix = "tindo"
+)
+
+// AddressPrefix - human-readable prefix of addresses on network current node belongs to
+var AddressPrefix = MainAddressPrefix
+
+var (
+	// ErrInvalidAddress - returned when parsing malformed address
+	ErrInvalidAddress = errors.New("invalid address")
+
+	// ErrAddressChecksum - returned when address checksum does not match, e.g. because address was mistyped
+	ErrAddressChecksum = errors.New("invalid address checksum; address mistyped?")
+
+	// ErrAddressNetwork - returned when address belongs to other network
+	ErrAddressNetwork = errors.New("address belongs to other network")
+)
+
+// String - textual address on current network (see AddressPrefix)
+func (a Address) String() string {
+	return a.Encode(AddressPrefix)
+}
+
+// Encode - textual address under specified network prefix
+func (a Address) Encode(prefix string) string {
+	s, _ := Bech32Encode(prefix, a[:])
+	return s
+}
+
+// Hex - hex encoding of raw address
+func (a Address) Hex() string {
+	return hex.EncodeToString(a[:])
+}
+
+// Bytes - byte slice of address
+func (a Address) Bytes() []byte { return a[:] }
+
+// Format - implement fmt.Formatter; %x & %X print raw hex, other verbs print textual address
+func (a Address) Format(s fmt.State, c rune) {
+	formatBytes(s, c, a[:], a.String())
+}
+
+// IsZero - check if address is unset
+func (a Address) IsZero() bool {
+	return a == Address{}
+}
+
+// Cmp - compare addresses byte-wise, returning -1, 0 or 1
+func (a Address) Cmp(other Address) int {
+	return bytes.Compare(a[:], other[:])
+}
+
+// Less - check if address sorts before other address
+func (a Address) Less(other Address) bool {
+	return a.Cmp(other) < 0
+}
+
+// MarshalText - implement encoding.TextMarshaler; raw hex is used so encoded data does not
+// depend on network prefix
+func (a Address) MarshalText() ([]byte, error) {
+	return []byte(a.Hex()), nil
+}
+
+// UnmarshalText - implement encoding.TextUnmarshaler, accepting raw hex or textual address on
+// current network
+func (a *Address) UnmarshalText(text []byte) error {
+	parsed, err := ParseHexAddress(string(text))
+
+	if err != nil {
+		parsed, err = ParseAddress(string(text))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	*a = parsed
+
+	return nil
+}
+
+// MarshalBinary - implement encoding.BinaryMarshaler (raw bytes)
+func (a Address) MarshalBinary() ([]byte, error) {
+	return append([]byte(nil), a[:]...), nil
+}
+
+// UnmarshalBinary - implement encoding.BinaryUnmarshaler
+func (a *Address) UnmarshalBinary(b []byte) error {
+	if len(b) != AddressLength {
+		return ErrInvalidAddress
+	}
+
+	copy(a[:], b)
+
+	return nil
+}
+
+// ParseAddress - parse textual address, requiring prefix of current network
+func ParseAddress(s string) (Address, error) {
+	return ParseAddressWithPrefix(s, AddressPrefix)
+}
+
+// ParseAddressWithPrefix - parse textual address, requiring specified network prefix
+func ParseAddressWithPrefix(s string, prefix string) (Address, error) {
+	decodedPrefix, data, err := Bech32Decode(strings.TrimSpace(s))
+
+	if err == ErrBech32Checksum {
+		return Address{}, ErrAddressChecksum
+	}
+
+	if err != nil || len(data) != AddressLength {
+		return Address{}, ErrInvalidAddress
+	}
+
+	if decodedPrefix != strings.ToLower(prefix) {
+		return Address{}, ErrAddressNetwork
+	}
+
+	return BytesToAddress(data), nil
+}
+
+// ParseHexAddress - parse raw hex address (with optional 0x prefix), rejecting invalid hex &
+// addresses of wrong length
+func ParseHexAddress(s string) (Address, error) {
+	b, err := DecodeHex(s)
+
+	if err != nil || len(b) != AddressLength {
+		return Address{}, ErrInvalidAddress
+	}
+
+	return BytesToAddress(b), nil
+}
+
+// DecodeHex - decode hex string with optional 0x prefix, returning error on invalid input
+func DecodeHex(s string) ([]byte, error) {
+	if len(s) > 1 && (s[0:2] == "0x" || s[0:2] == "0X") {
+		s = s[2:]
+	}
+
+	return hex.DecodeString(s)
+}
@@ -0,0 +1,60 @@
+package common
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBech32Decode(t *testing.T) {
+	if prefix, data, err := Bech32Decode("A1LQFN3A"); err != nil || prefix != "a" || len(data) != 0 {
+		t.Errorf("bech32m test vector rejected (%v)", err)
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	addr := HexToAddress("4920616d204d697473756b6f204d6567756d69")
+	encoded := addr.String()
+
+	if !strings.HasPrefix(encoded, MainAddressPrefix+"1") {
+		t.Fatalf("unexpected address encoding %s", encoded)
+	}
+
+	mistyped := []byte(encoded)
+	mistyped[10] = map[bool]byte{true: 'q', false: 'p'}[mistyped[10] != 'q']
+
+	tests := []struct {
+		name  string
+		input string
+		err   error
+	}{
+		{"round trip", encoded, nil},
+		{"mistyped", string(mistyped), ErrAddressChecksum},
+		{"test network", addr.Encode(TestAddressPrefix), ErrAddressNetwork},
+	}
+
+	for _, test := range tests {
+		decoded, err := ParseAddress(test.input)
+
+		if err != test.err || (err == nil && decoded != addr) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestParseHexAddress(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+	}{
+		{"4920616d204d697473756b6f204d6567756d6900", nil},
+		{"0x4920616d204d697473756b6f204d6567756d6900", nil},
+		{"0xzz20616d204d697473756b6f204d6567756d69", ErrInvalidAddress},
+		{"4920616d", ErrInvalidAddress},
+	}
+
+	for _, test := range tests {
+		if _, err := ParseHexAddress(test.input); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.input, test.err, err)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package common
+
+import (
+	"errors"
+	"strings"
+)
+
+// Bech32m (BIP-350) encoding: human-readable prefix, separator "1", base32 data & six-character
+// checksum detecting any error affecting up to four characters.
+
+const (
+	bech32Charset   = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	bech32mConst    = 0x2bc830a3
+	bech32MaxLength = 90
+)
+
+var (
+	// ErrBech32Checksum - returned when decoding bech32 string with invalid checksum
+	ErrBech32Checksum = errors.New("invalid checksum")
+
+	// ErrBech32Format - returned when decoding malformed bech32 string
+	ErrBech32Format = errors.New("malformed bech32 string")
+)
+
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+	chk := uint32(1)
+
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+
+		for x := 0; x < 5; x++ {
+			if (top>>uint(x))&1 == 1 {
+				chk ^= generator[x]
+			}
+		}
+	}
+
+	return chk
+}
+
+func bech32ExpandPrefix(prefix string) []byte {
+	expanded := make([]byte, 0, 2*len(prefix)+1)
+
+	for x := 0; x < len(prefix); x++ {
+		expanded = append(expanded, prefix[x]>>5)
+	}
+
+	expanded = append(expanded, 0)
+
+	for x := 0; x < len(prefix); x++ {
+		expanded = append(expanded, prefix[x]&31)
+	}
+
+	return expanded
+}
+
+// Bech32Encode - encode data under human-readable prefix
+func Bech32Encode(prefix string, data []byte) (string, error) {
+	values, err := convertBits(data, 8, 5, true)
+
+	if err != nil {
+		return "", err
+	}
+
+	prefix = strings.ToLower(prefix)
+
+	if prefix == "" || len(prefix)+1+len(values)+6 > bech32MaxLength {
+		return "", ErrBech32Format
+	}
+
+	polymod := bech32Polymod(append(append(bech32ExpandPrefix(prefix), values...), 0, 0, 0, 0, 0, 0)) ^ bech32mConst
+
+	var encoded strings.Builder
+
+	encoded.WriteString(prefix)
+	encoded.WriteByte('1')
+
+	for _, v := range values {
+		encoded.WriteByte(bech32Charset[v])
+	}
+
+	for x := 0; x < 6; x++ {
+		encoded.WriteByte(bech32Charset[(polymod>>uint(5*(5-x)))&31])
+	}
+
+	return encoded.String(), nil
+}
+
+// Bech32Decode - decode bech32 string into human-readable prefix & data, verifying checksum
+func Bech32Decode(s string) (string, []byte, error) {
+	if len(s) > bech32MaxLength || (strings.ToLower(s) != s && strings.ToUpper(s) != s) {
+		return "", nil, ErrBech32Format // Mixed case is never valid
+	}
+
+	s = strings.ToLower(s)
+	sep := strings.LastIndexByte(s, '1')
+
+	if sep < 1 || sep+7 > len(s) {
+		return "", nil, ErrBech32Format
+	}
+
+	prefix := s[:sep]
+
+	for x := 0; x < len(prefix); x++ {
+		if prefix[x] < 33 || prefix[x] > 126 {
+			return "", nil, ErrBech32Format
+		}
+	}
+
+	values := make([]byte, 0, len(s)-sep-1)
+
+	for x := sep + 1; x < len(s); x++ {
+		v := strings.IndexByte(bech32Charset, s[x])
+
+		if v < 0 {
+			return "", nil, ErrBech32Format
+		}
+
+		values = append(values, byte(v))
+	}
+
+	if bech32Polymod(append(bech32ExpandPrefix(prefix), values...)) != bech32mConst {
+		return "", nil, ErrBech32Checksum
+	}
+
+	data, err := convertBits(values[:len(values)-6], 5, 8, false)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return prefix, data, nil
+}
+
+// convertBits - regroup data from groups of fromBits into groups of toBits
+func convertBits(data []byte, fromBits uint, toBits uint, pad bool) ([]byte, error) {
+	var acc uint32
+	var bits uint
+
+	maxv := uint32(1)<<toBits - 1
+	converted := make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+
+	for _, v := range data {
+		if uint32(v)>>fromBits != 0 {
+			return nil, ErrBech32Format
+		}
+
+		acc = acc<<fromBits | uint32(v)
+		bits += fromBits
+
+		for bits >= toBits {
+			bits -= toBits
+			converted = append(converted, byte(acc>>bits&maxv))
+		}
+	}
+
+	if pad {
+		if bits > 0 {
+			converted = append(converted, byte(acc<<(toBits-bits)&maxv))
+		}
+	} else if bits >= fromBits || (acc<<(toBits-bits))&maxv != 0 {
+		return nil, ErrBech32Format // Excess or non-zero padding
+	}
+
+	return converted, nil
+}
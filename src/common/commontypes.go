@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/big"
+	"strings"
 )
 
 const (
@@ -33,7 +34,7 @@ func BytesToAddress(b []byte) Address {
 	return a
 }
 
-// SetBytes - Sets the address to the value of b.
+// SetBytes - Sets the address to the value of b; b is right-aligned, dropping leading bytes if too long.
 func (a *Address) SetBytes(b []byte) {
 	if len(b) > len(a) {
 		b = b[len(b)-AddressLength:]
@@ -47,7 +48,7 @@ func BigToAddress(b *big.Int) Address { return BytesToAddress(b.Bytes()) }
 // HexToAddress - Convert hex string to Address
 func HexToAddress(s string) Address { return BytesToAddress(FromHex(s)) }
 
-// FromHex - Generate byte array from hex string
+// FromHex - Generate byte array from hex string; invalid hex yields empty array (see DecodeHex)
 func FromHex(s string) []byte {
 	if len(s) > 1 {
 		if s[0:2] == "0x" || s[0:2] == "0X" {
@@ -60,12 +61,6 @@ func FromHex(s string) []byte {
 	return Hex2Bytes(s)
 }
 
-// IntToPointer - return int with pointer
-func IntToPointer(i int) *int {
-	p := i
-	return &p
-}
-
 // Hex2Bytes - convert Hex string to byte array
 func Hex2Bytes(str string) []byte {
 	h, _ := hex.DecodeString(str)
@@ -73,6 +68,29 @@ func Hex2Bytes(str string) []byte {
 	return h
 }
 
+// formatBytes - shared fmt.Formatter implementation of byte-array primitives; %x & %X print raw
+// hex (0x-prefixed with '#' flag), other verbs print str
+func formatBytes(s fmt.State, c rune, b []byte, str string) {
+	switch c {
+	case 'x', 'X':
+		if s.Flag('#') {
+			s.Write([]byte("0x"))
+		}
+
+		encoded := hex.EncodeToString(b)
+
+		if c == 'X' {
+			encoded = strings.ToUpper(encoded)
+		}
+
+		s.Write([]byte(encoded))
+	case 'q':
+		fmt.Fprintf(s, "%q", str)
+	default:
+		s.Write([]byte(str))
+	}
+}
+
 // ThrowWarning - print warning to console
 func ThrowWarning(str string) {
 	fmt.Println(str)
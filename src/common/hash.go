@@ -0,0 +1,107 @@
+package common
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidHash - returned when decoding malformed hash
+var ErrInvalidHash = errors.New("invalid hash")
+
+// BytesToHash - Set hash instance to byte array.
+func BytesToHash(b []byte) Hash {
+	var h Hash
+	h.SetBytes(b)
+	return h
+}
+
+// HexToHash - Convert hex string to Hash; invalid hex yields empty hash (see ParseHash)
+func HexToHash(s string) Hash { return BytesToHash(FromHex(s)) }
+
+// ParseHash - parse hex hash (with optional 0x prefix), rejecting invalid hex & hashes of wrong length
+func ParseHash(s string) (Hash, error) {
+	b, err := DecodeHex(s)
+
+	if err != nil || len(b) != HashLength {
+		return Hash{}, ErrInvalidHash
+	}
+
+	return BytesToHash(b), nil
+}
+
+// SetBytes - Sets the hash to the value of b; b is right-aligned, dropping leading bytes if too long.
+func (h *Hash) SetBytes(b []byte) {
+	if len(b) > len(h) {
+		b = b[len(b)-HashLength:]
+	}
+	copy(h[HashLength-len(b):], b)
+}
+
+// Bytes - byte slice of hash
+func (h Hash) Bytes() []byte { return h[:] }
+
+// Hex - hex encoding of hash
+func (h Hash) Hex() string {
+	return hex.EncodeToString(h[:])
+}
+
+// String - hex encoding of hash
+func (h Hash) String() string {
+	return h.Hex()
+}
+
+// Format - implement fmt.Formatter; hash is printed in hex for all verbs
+func (h Hash) Format(s fmt.State, c rune) {
+	formatBytes(s, c, h[:], h.Hex())
+}
+
+// IsZero - check if hash is unset
+func (h Hash) IsZero() bool {
+	return h == Hash{}
+}
+
+// Cmp - compare hashes byte-wise, returning -1, 0 or 1
+func (h Hash) Cmp(other Hash) int {
+	return bytes.Compare(h[:], other[:])
+}
+
+// Less - check if hash sorts before other hash
+func (h Hash) Less(other Hash) bool {
+	return h.Cmp(other) < 0
+}
+
+// MarshalText - implement encoding.TextMarshaler (hex); also used for JSON
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.Hex()), nil
+}
+
+// UnmarshalText - implement encoding.TextUnmarshaler (hex, optionally 0x-prefixed)
+func (h *Hash) UnmarshalText(text []byte) error {
+	parsed, err := ParseHash(string(text))
+
+	if err != nil {
+		return err
+	}
+
+	*h = parsed
+
+	return nil
+}
+
+// MarshalBinary - implement encoding.BinaryMarshaler (raw bytes)
+func (h Hash) MarshalBinary() ([]byte, error) {
+	return append([]byte(nil), h[:]...), nil
+}
+
+// UnmarshalBinary - implement encoding.BinaryUnmarshaler
+func (h *Hash) UnmarshalBinary(b []byte) error {
+	if len(b) != HashLength {
+		return ErrInvalidHash
+	}
+
+	copy(h[:], b)
+
+	return nil
+}
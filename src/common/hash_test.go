@@ -0,0 +1,63 @@
+package common
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBytesToHash(t *testing.T) {
+	long := make([]byte, HashLength+4)
+	long[len(long)-1] = 0xff
+
+	tests := []struct {
+		name  string
+		input []byte
+		last  byte
+		prev  byte
+	}{
+		{"short input right-aligned", []byte{0x01, 0x02}, 0x02, 0x01},
+		{"long input truncated from left", long, 0xff, 0x00},
+	}
+
+	for _, test := range tests {
+		if hash := BytesToHash(test.input); hash[HashLength-1] != test.last || hash[HashLength-2] != test.prev {
+			t.Errorf("%s: got %x", test.name, hash)
+		}
+	}
+}
+
+func TestHashEncoding(t *testing.T) {
+	var hash Hash
+	hash.SetBytes([]byte{0x01, 0x02})
+
+	if fmt.Sprintf("%#x", hash) != "0x"+hash.Hex() || fmt.Sprintf("%v", hash) != hash.Hex() {
+		t.Errorf("unexpected hash formatting %v", hash)
+	}
+
+	bin, _ := hash.MarshalBinary()
+
+	var fromBinary Hash
+
+	if err := fromBinary.UnmarshalBinary(bin); err != nil || fromBinary != hash || fromBinary.UnmarshalBinary(bin[1:]) != ErrInvalidHash {
+		t.Errorf("hash did not round trip through binary encoding (%v)", err)
+	}
+}
+
+func TestTextEncoding(t *testing.T) {
+	addr := HexToAddress("4920616d204d697473756b6f204d6567756d69")
+
+	var fromText Address
+
+	if err := fromText.UnmarshalText([]byte(addr.String())); err != nil || fromText != addr {
+		t.Errorf("textual address not accepted (%v)", err)
+	}
+
+	id := Identifier{0xab, 0x01}
+	text, _ := id.MarshalText()
+
+	var decodedID Identifier
+
+	if err := decodedID.UnmarshalText(text); err != nil || !decodedID.Equal(id) || id.Cmp(Identifier{0xab, 0x02}) >= 0 {
+		t.Errorf("identifier did not round trip (%v)", err)
+	}
+}
@@ -0,0 +1,72 @@
+package common
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+)
+
+// HexToIdentifier - decode identifier from hex string
+func HexToIdentifier(s string) (Identifier, error) {
+	b, err := DecodeHex(s)
+
+	if err != nil || len(b) == 0 {
+		return nil, err // Empty identifier is nil (default chain)
+	}
+
+	return b, nil
+}
+
+// String - hex encoding of identifier (empty for default chain)
+func (id Identifier) String() string {
+	return hex.EncodeToString(id)
+}
+
+// Format - implement fmt.Formatter; identifier is printed in hex for all verbs
+func (id Identifier) Format(s fmt.State, c rune) {
+	formatBytes(s, c, id, id.String())
+}
+
+// Equal - check if identifiers are identical; nil & empty identifiers are equal
+func (id Identifier) Equal(other Identifier) bool {
+	return bytes.Equal(id, other)
+}
+
+// Cmp - compare identifiers byte-wise, returning -1, 0 or 1
+func (id Identifier) Cmp(other Identifier) int {
+	return bytes.Compare(id, other)
+}
+
+// MarshalText - implement encoding.TextMarshaler (hex); also used for JSON
+func (id Identifier) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText - implement encoding.TextUnmarshaler (hex, optionally 0x-prefixed)
+func (id *Identifier) UnmarshalText(text []byte) error {
+	decoded, err := HexToIdentifier(string(text))
+
+	if err != nil {
+		return err
+	}
+
+	*id = decoded
+
+	return nil
+}
+
+// MarshalBinary - implement encoding.BinaryMarshaler (raw bytes)
+func (id Identifier) MarshalBinary() ([]byte, error) {
+	return append([]byte(nil), id...), nil
+}
+
+// UnmarshalBinary - implement encoding.BinaryUnmarshaler
+func (id *Identifier) UnmarshalBinary(b []byte) error {
+	if len(b) == 0 {
+		*id = nil
+	} else {
+		*id = append(Identifier(nil), b...)
+	}
+
+	return nil
+}
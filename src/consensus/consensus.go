@@ -1,10 +1,15 @@
 package consensus
 
 import (
+	"crypto/ecdsa"
+	"math"
 	"reflect"
 
 	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/core/mempool"
+	"github.com/mitsukomegumi/indo-go/src/core/token"
 	"github.com/mitsukomegumi/indo-go/src/core/types"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
 )
 
 // WitnessTransaction - add witness data to specified transaction if verified
@@ -30,6 +35,143 @@ func WitnessTransaction(tx *types.Transaction, witness *types.Witness) {
 	}
 }
 
+// WitnessPending - witness up to count highest priority transactions from mempool, moving them into chain;
+// transactions not signed by their sender, violating chain's spam policy, or on token chains no longer
+// valid against token balances, are dropped from mempool instead
+func WitnessPending(pool *mempool.Mempool, ch *types.Chain, witness *types.Witness, count int) []*types.Transaction {
+	pending := pool.Pending(count)
+
+	var ledger *token.Ledger
+
+	if token.IsTokenChain(ch) {
+		var err error
+
+		ledger, err = token.LedgerFromChain(ch)
+
+		if err != nil {
+			common.ThrowWarning("token chain invalid; not witnessing: " + err.Error())
+			return nil
+		}
+	}
+
+	var witnessed []*types.Transaction
+
+	for _, tx := range pending {
+		pool.Remove(tx.Hash())
+
+		err := tx.Verify()
+
+		if err != nil {
+			common.ThrowWarning("dropping unauthenticated transaction: " + err.Error())
+			continue
+		}
+
+		err = ch.CheckSpam(tx, 0) // Transactions witnessed earlier in batch are already in chain
+
+		if err != nil {
+			common.ThrowWarning("dropping spam transaction: " + err.Error())
+			continue
+		}
+
+		if ledger != nil {
+			err = ledger.Apply(tx)
+
+			if err != nil {
+				common.ThrowWarning("dropping invalid token transaction: " + err.Error())
+				continue
+			}
+		}
+
+		WitnessTransaction(tx, witness)
+		ch.AddTransaction(tx)
+
+		witnessed = append(witnessed, tx)
+	}
+
+	return witnessed
+}
+
+// CheckpointIfDue - once enough transactions were added since latest checkpoint, add new checkpoint
+// to chain signed with specified witness key; returns nil if no checkpoint is due
+func CheckpointIfDue(ch *types.Chain, key *ecdsa.PrivateKey) (*types.Header, error) {
+	if !ch.CheckpointDue() {
+		return nil, nil
+	}
+
+	h, err := ch.NewCheckpoint()
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.Sign(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = ch.AddCheckpoint(h, trustingSelf(ch, key))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// SnapshotIfDue - once latest checkpoint is due for snapshot, add snapshot of chain state at it signed
+// with specified witness key, pruning covered transactions if snapshot policy says so; returns nil if
+// no snapshot is due
+func SnapshotIfDue(ch *types.Chain, key *ecdsa.PrivateKey) (*types.Snapshot, error) {
+	if !ch.SnapshotDue() {
+		return nil, nil
+	}
+
+	s, err := ch.NewSnapshot(token.Issuance(ch.Identifier))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if token.IsTokenChain(ch) {
+		s.Data, err = token.SnapshotData(ch)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.Sign(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = ch.AddSnapshot(s, trustingSelf(ch, key))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if types.Snapshotting.Prune {
+		ch.Prune()
+	}
+
+	return s, nil
+}
+
+// trustingSelf - witness reputation as recorded by chain, with full trust in signatures by specified key
+func trustingSelf(ch *types.Chain, key *ecdsa.PrivateKey) types.ReputationFunc {
+	self := discovery.PubkeyToNodeID(&key.PublicKey)
+
+	return func(id discovery.NodeID) int {
+		if id == self {
+			return math.MaxInt32 // Witness trusts own signature
+		}
+		return ch.WitnessReputation(id)
+	}
+}
+
 // CalculateWeight - calculate weight for transaction based on current weight or implied weight
 func CalculateWeight(tx *types.Transaction) {
 
@@ -43,10 +185,9 @@ func CalculateWitnessWeight(witness *types.Witness) *int {
 
 // VerifyTransaction - checks validity of transaction, returning bool
 func VerifyTransaction(tx *types.Transaction) bool {
-	balance := *types.GetBalance(tx.SendingAccount)
-	amountTransacted := *tx.Data.Amount
+	balance := types.GetBalance(tx.SendingAccount)
 
-	if balance <= amountTransacted {
+	if balance.Cmp(tx.Data.Amount) <= 0 {
 		return true
 	}
 	return false
@@ -0,0 +1,228 @@
+package consensus
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/core/mempool"
+	"github.com/mitsukomegumi/indo-go/src/core/token"
+	"github.com/mitsukomegumi/indo-go/src/core/types"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+)
+
+// testAccount - fresh account & its key
+func testAccount(t *testing.T) (*types.Account, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return types.NewAccount(types.PubkeyToAddress(&key.PublicKey)), key
+}
+
+// signed - sign transaction with sender's key
+func signed(t *testing.T, tx *types.Transaction, err error, key *ecdsa.PrivateKey) *types.Transaction {
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.SignWith(key); err != nil {
+		t.Fatal(err)
+	}
+
+	return tx
+}
+
+// signer - signing function of key
+func signer(key *ecdsa.PrivateKey) func(*types.Transaction) error {
+	return func(tx *types.Transaction) error {
+		return tx.SignWith(key)
+	}
+}
+
+func TestWitnessPendingToken(t *testing.T) {
+	issuer, issuerKey := testAccount(t)
+	holder, holderKey := testAccount(t)
+
+	tok, err := token.NewToken("Creator Fund", "fund", types.NewAmount(1000), issuer.Address)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch, err := tok.NewChain(*issuer, signer(issuerKey), nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transfer, err := tok.TransferTransaction(1, *issuer, holder.Address, types.NewAmount(400))
+	transfer = signed(t, transfer, err, issuerKey)
+
+	overdraw, err := tok.TransferTransaction(1, *holder, issuer.Address, types.NewAmount(451))
+	overdraw = signed(t, overdraw, err, holderKey)
+
+	pool := mempool.NewMempool(10, nil)
+	pool.Add(overdraw)
+	pool.Add(transfer)
+
+	witness := types.NewWitness(1000, types.HexToSignature("01"), 100)
+
+	if witnessed := WitnessPending(pool, ch, &witness, 2); len(witnessed) != 1 || witnessed[0] != transfer || pool.Len() != 0 {
+		t.Errorf("invalid token transaction witnessed into chain")
+	}
+}
+
+func TestWitnessPendingRateLimit(t *testing.T) {
+	sender, key := testAccount(t)
+	witness := types.NewWitness(1000, types.HexToSignature("01"), 100)
+
+	ch := &types.Chain{Identifier: common.Identifier{0x5a}, SpamPolicy: types.SpamPolicy{Difficulty: 8, RateLimit: 2, RateWindow: time.Hour}}
+	pool := mempool.NewMempool(mempool.DefaultLimit, nil)
+
+	for x := 0; x < 3; x++ {
+		pending := types.NewTransaction(uint64(x), *sender, common.HexToAddress("04"), types.NewAmount(1), nil, nil, nil)
+		pending.SolveWork(8)
+		pool.Add(signed(t, pending, nil, key))
+	}
+
+	if witnessed := WitnessPending(pool, ch, &witness, 3); len(witnessed) != 2 || pool.Len() != 0 {
+		t.Errorf("rate limit not enforced when witnessing: %d witnessed", len(witnessed))
+	}
+}
+
+func TestCheckpointIfDue(t *testing.T) {
+	sender, key := testAccount(t)
+	witness := types.NewWitness(1000, types.HexToSignature("01"), 100)
+
+	ch := &types.Chain{NodeDb: &discovery.NodeDatabase{SelfRef: discovery.PubkeyToNodeID(&key.PublicKey)}}
+
+	addTransactions := func(count int) {
+		for x := 0; x < count; x++ {
+			tx := types.NewTransaction(uint64(ch.Version), *sender, common.HexToAddress("05"), types.NewAmount(1), nil, nil, nil)
+			WitnessTransaction(signed(t, tx, nil, key), &witness)
+			ch.AddTransaction(tx)
+		}
+	}
+
+	addTransactions(types.Checkpointing.Interval - 1)
+
+	if h, err := CheckpointIfDue(ch, key); h != nil || err != nil {
+		t.Fatalf("checkpoint created before due (%v)", err)
+	}
+
+	addTransactions(1)
+
+	h, err := CheckpointIfDue(ch, key)
+
+	if err != nil || h == nil || h.ToVersion != ch.Version || len(h.Signatures) != 1 || ch.LatestCheckpoint() != h {
+		t.Fatalf("checkpoint not created (%v)", err)
+	}
+
+	if h, err := CheckpointIfDue(ch, key); h != nil || err != nil {
+		t.Errorf("checkpoint created twice (%v)", err)
+	}
+}
+
+func TestSnapshotIfDue(t *testing.T) {
+	defer func(checkpointing types.CheckpointPolicy, snapshotting types.SnapshotPolicy) {
+		types.Checkpointing, types.Snapshotting = checkpointing, snapshotting
+	}(types.Checkpointing, types.Snapshotting)
+
+	types.Checkpointing.Interval = 2
+	types.Snapshotting = types.SnapshotPolicy{Interval: 2, Prune: true}
+
+	issuer, key := testAccount(t)
+	holder, _ := testAccount(t)
+
+	tok, err := token.NewToken("Snapshot Fund", "snap", types.NewAmount(1000), issuer.Address)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch, err := tok.NewChain(*issuer, signer(key), &discovery.NodeDatabase{SelfRef: discovery.PubkeyToNodeID(&key.PublicKey)})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	full := *ch
+	witness := types.NewWitness(1000, types.HexToSignature("0a"), 100)
+
+	for x := 1; x <= 5; x++ {
+		tx, err := tok.TransferTransaction(uint64(x), *issuer, holder.Address, types.NewAmount(10))
+
+		if x == 2 {
+			tx, err = tok.MintTransaction(uint64(x), *issuer, holder.Address, types.NewAmount(5))
+		}
+
+		WitnessTransaction(signed(t, tx, err, key), &witness)
+		ch.AddTransaction(tx)
+
+		if _, err := CheckpointIfDue(ch, key); err != nil {
+			t.Fatal(err)
+		}
+
+		if s, err := SnapshotIfDue(ch, key); err != nil {
+			t.Fatal(err)
+		} else if s != nil && s.Checkpoint%types.Snapshotting.Interval != 0 {
+			t.Errorf("snapshot taken at checkpoint %d", s.Checkpoint)
+		}
+
+		full.Transactions = append(full.Transactions, tx)
+	}
+
+	if ch.Snapshot == nil || ch.Snapshot.Checkpoint != 2 || ch.Snapshot.Version != 4 {
+		t.Fatalf("snapshot not taken at second checkpoint: %+v", ch.Snapshot)
+	}
+
+	if len(ch.Transactions) != 2 || ch.PrunedVersion() != 4 {
+		t.Errorf("transactions covered by snapshot not pruned: %d held", len(ch.Transactions))
+	}
+
+	if balance, _ := ch.Snapshot.Account(holder.Address).Balance(); balance.String() != "25" {
+		t.Errorf("wrong holder balance in snapshot: %s", balance)
+	}
+
+	pruned, err := token.LedgerFromChain(ch)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	complete, err := token.LedgerFromChain(&full)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pruned.Token.Symbol != "SNAP" || pruned.Supply.Cmp(complete.Supply) != 0 || pruned.BalanceOf(holder.Address).Cmp(complete.BalanceOf(holder.Address)) != 0 || pruned.BalanceOf(issuer.Address).Cmp(complete.BalanceOf(issuer.Address)) != 0 {
+		t.Errorf("token state replayed from snapshot differs: supply %s, balances %v", pruned.Supply, pruned.Balances)
+	}
+}
+
+func TestWitnessPendingForgedSender(t *testing.T) {
+	sender, key := testAccount(t)
+	victim, _ := testAccount(t)
+	witness := types.NewWitness(1000, types.HexToSignature("01"), 100)
+
+	valid := signed(t, types.NewTransaction(0, *sender, common.HexToAddress("04"), types.NewAmount(1), nil, nil, nil), nil, key)
+
+	forged := signed(t, types.NewTransaction(1, *sender, common.HexToAddress("04"), types.NewAmount(1), nil, nil, nil), nil, key)
+	forged.SendingAccount = *victim
+
+	ch := &types.Chain{}
+	pool := mempool.NewMempool(mempool.DefaultLimit, nil)
+	pool.Add(valid)
+	pool.Add(forged)
+
+	if witnessed := WitnessPending(pool, ch, &witness, 2); len(witnessed) != 1 || witnessed[0] != valid || pool.Len() != 0 {
+		t.Errorf("transaction with forged sender witnessed into chain")
+	}
+}
@@ -0,0 +1,250 @@
+package mempool
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/core/types"
+)
+
+const (
+	// DefaultLimit - default maximum number of transactions held in pool
+	DefaultLimit = 10000
+
+	// DefaultExpiry - default time transaction may wait in pool before being evicted
+	DefaultExpiry = 24 * time.Hour
+)
+
+var (
+	// ErrDuplicate - returned when transaction is already held in pool
+	ErrDuplicate = errors.New("transaction already in mempool")
+
+	// ErrPoolFull - returned when pool is full & transaction has lower priority than all held transactions
+	ErrPoolFull = errors.New("mempool full; transaction priority too low")
+)
+
+// Mempool - bounded pool of received transactions awaiting witnessing & chain inclusion
+type Mempool struct {
+	Limit  int           // Maximum number of held transactions
+	Expiry time.Duration // Time after which held transactions are evicted
+
+	Validate func(*types.Transaction) error // Check run on every transaction entering pool
+
+	mu      sync.RWMutex
+	entries map[common.Hash]*entry
+}
+
+// entry - transaction held in pool, alongside time it entered pool & its proof-of-work
+type entry struct {
+	tx    *types.Transaction
+	added time.Time
+	work  int
+}
+
+// NewMempool - return new, empty mempool holding at most limit transactions
+func NewMempool(limit int, validate func(*types.Transaction) error) *Mempool {
+	if validate == nil {
+		validate = ValidateTransaction
+	}
+
+	return &Mempool{Limit: limit, Expiry: DefaultExpiry, Validate: validate, entries: make(map[common.Hash]*entry)}
+}
+
+// ValidateTransaction - default mempool entry check; verifies transaction is well formed & signed by
+// its sender, so held transactions only count towards rate limit of authenticated senders (see CountFrom)
+func ValidateTransaction(tx *types.Transaction) error {
+	if reflect.ValueOf(tx).IsNil() {
+		return errors.New("invalid transaction: nil")
+	}
+
+	if tx.Hash() == (common.Hash{}) {
+		return errors.New("invalid transaction: missing hash")
+	}
+
+	if tx.Data.Recipient == nil && len(tx.Data.Payload) == 0 {
+		return errors.New("invalid transaction: no recipient or payload")
+	}
+
+	return tx.Verify()
+}
+
+// Add - validate transaction & add to pool; when pool is full, lowest priority transaction is evicted
+func (pool *Mempool) Add(tx *types.Transaction) error {
+	err := pool.Validate(tx)
+
+	if err != nil {
+		return err
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	hash := tx.Hash()
+
+	if _, found := pool.entries[hash]; found {
+		return ErrDuplicate
+	}
+
+	newEntry := &entry{tx: tx, added: time.Now(), work: tx.WorkBits()}
+
+	if len(pool.entries) >= pool.Limit {
+		pool.expire(newEntry.added)
+	}
+
+	if len(pool.entries) >= pool.Limit {
+		lowest := pool.lowest()
+
+		if lowest == nil || !higherPriority(newEntry, lowest) {
+			return ErrPoolFull
+		}
+
+		delete(pool.entries, lowest.tx.Hash())
+	}
+
+	pool.entries[hash] = newEntry
+
+	return nil
+}
+
+// Remove - remove transaction with specified hash from pool, returning false if not held
+func (pool *Mempool) Remove(hash common.Hash) bool {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if _, found := pool.entries[hash]; !found {
+		return false
+	}
+
+	delete(pool.entries, hash)
+
+	return true
+}
+
+// Get - return held transaction with specified hash, or nil if not held
+func (pool *Mempool) Get(hash common.Hash) *types.Transaction {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	if e, found := pool.entries[hash]; found {
+		return e.tx
+	}
+
+	return nil
+}
+
+// Has - check if transaction with specified hash is held in pool
+func (pool *Mempool) Has(hash common.Hash) bool {
+	return pool.Get(hash) != nil
+}
+
+// Len - number of transactions held in pool
+func (pool *Mempool) Len() int {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return len(pool.entries)
+}
+
+// CountFrom - number of held transactions sent by specified account & received after specified time,
+// excluding transaction with specified hash
+func (pool *Mempool) CountFrom(addr common.Address, exclude common.Hash, since time.Time) int {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	count := 0
+
+	for hash, e := range pool.entries {
+		if hash != exclude && e.tx.SendingAccount.Address == addr && e.added.After(since) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// List - return all held transactions, highest priority first
+func (pool *Mempool) List() []*types.Transaction {
+	return pool.Pending(-1)
+}
+
+// Pending - return up to count held transactions, highest priority first (all if count < 0)
+func (pool *Mempool) Pending(count int) []*types.Transaction {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	sorted := make([]*entry, 0, len(pool.entries))
+
+	for _, e := range pool.entries {
+		sorted = append(sorted, e)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return higherPriority(sorted[i], sorted[j])
+	})
+
+	if count >= 0 && len(sorted) > count {
+		sorted = sorted[:count]
+	}
+
+	txs := make([]*types.Transaction, len(sorted))
+
+	for x, e := range sorted {
+		txs[x] = e.tx
+	}
+
+	return txs
+}
+
+// Prune - evict all transactions held longer than pool expiry, returning number evicted
+func (pool *Mempool) Prune() int {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	return pool.expire(time.Now())
+}
+
+func (pool *Mempool) expire(now time.Time) int {
+	evicted := 0
+
+	for hash, e := range pool.entries {
+		if pool.Expiry > 0 && now.Sub(e.added) > pool.Expiry {
+			delete(pool.entries, hash)
+			evicted++
+		}
+	}
+
+	return evicted
+}
+
+func (pool *Mempool) lowest() *entry {
+	var lowest *entry
+
+	for _, e := range pool.entries {
+		if lowest == nil || higherPriority(lowest, e) {
+			lowest = e
+		}
+	}
+
+	return lowest
+}
+
+// higherPriority - check if a should be witnessed before b; more proof-of-work first, then earliest
+// received first. Priority is only derived from values computed locally, as weight & timestamp of
+// relayed transactions are set by peers.
+func higherPriority(a *entry, b *entry) bool {
+	if a.work != b.work {
+		return a.work > b.work
+	}
+
+	if !a.added.Equal(b.added) {
+		return a.added.Before(b.added)
+	}
+
+	ha, hb := a.tx.Hash(), b.tx.Hash()
+
+	return string(ha[:]) < string(hb[:])
+}
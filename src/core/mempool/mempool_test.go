@@ -0,0 +1,91 @@
+package mempool
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/core/types"
+)
+
+// testTransaction - transaction of specified nonce with at least specified proof-of-work (less than 8 bits
+// if zero), signed by sending account's key
+func testTransaction(t *testing.T, key *ecdsa.PrivateKey, nonce uint64, work uint8) *types.Transaction {
+	tx := types.NewTransaction(nonce, *types.NewAccount(types.PubkeyToAddress(&key.PublicKey)), common.HexToAddress("02"), types.NewAmount(10), nil, nil, nil)
+
+	for work == 0 && tx.WorkBits() >= 8 {
+		tx.Data.Nonce += 100
+	}
+
+	if err := tx.SolveWork(work); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.SignWith(key); err != nil {
+		t.Fatal(err)
+	}
+
+	return tx
+}
+
+func TestMempool(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	low := testTransaction(t, key, 1, 0)
+	high := testTransaction(t, key, 2, 8)
+	highest := testTransaction(t, key, 3, 12)
+
+	inflated := testTransaction(t, key, 4, 0)
+	inflated.Weight = 1 << 20 // Weight is set by peers, so never affects priority
+
+	pool := NewMempool(2, nil)
+
+	tests := []struct {
+		name string
+		tx   *types.Transaction
+		err  error
+	}{
+		{"valid", low, nil},
+		{"duplicate", low, ErrDuplicate},
+		{"fills pool", high, nil},
+		{"evicts lowest priority", highest, nil},
+		{"lower than held", testTransaction(t, key, 5, 0), ErrPoolFull},
+		{"inflated weight", inflated, ErrPoolFull},
+		{"unsigned", types.NewTransaction(6, *types.NewAccount(common.HexToAddress("03")), common.HexToAddress("02"), types.NewAmount(1), nil, nil, nil), types.ErrUnsigned},
+	}
+
+	for _, test := range tests {
+		if err := pool.Add(test.tx); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+
+	if pool.Len() != 2 || pool.Has(low.Hash()) {
+		t.Errorf("lowest priority transaction not evicted")
+	}
+
+	if pending := pool.Pending(1); len(pending) != 1 || pending[0] != highest {
+		t.Errorf("pending transactions not ordered by priority")
+	}
+
+	sender := highest.SendingAccount.Address
+
+	if pool.CountFrom(sender, common.Hash{}, time.Now().Add(-time.Minute)) != 2 || pool.CountFrom(sender, high.Hash(), time.Now().Add(-time.Minute)) != 1 {
+		t.Errorf("transactions received within window not counted")
+	}
+
+	if pool.CountFrom(sender, common.Hash{}, time.Now().Add(time.Minute)) != 0 {
+		t.Errorf("transactions received before window counted")
+	}
+
+	if !pool.Remove(high.Hash()) || pool.Remove(high.Hash()) || pool.Len() != 1 {
+		t.Errorf("transaction not removed")
+	}
+}
@@ -0,0 +1,145 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+)
+
+// Leaves & interior nodes are hashed under distinct prefixes, so interior node can never be passed off as leaf.
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// Root - root of Merkle tree over specified leaves (zero hash if none); unpaired node at end of
+// level is carried up to next level unchanged
+func Root(leaves []common.Hash) common.Hash {
+	if len(leaves) == 0 {
+		return common.Hash{}
+	}
+
+	level := make([]common.Hash, len(leaves))
+
+	for x, leaf := range leaves {
+		level[x] = hashLeaf(leaf)
+	}
+
+	for len(level) > 1 {
+		level = nextLevel(level)
+	}
+
+	return level[0]
+}
+
+// Proof - inclusion proof of leaf in Merkle tree: siblings of nodes on path from leaf to root,
+// bottom up; levels at which path node is carried up unpaired have no sibling
+type Proof struct {
+	Index    int           `json:"index"`  // Position of leaf in tree
+	Leaves   int           `json:"leaves"` // Number of leaves in tree
+	Siblings []common.Hash `json:"siblings"`
+}
+
+var (
+	// ErrIndexOutOfRange - returned when proving leaf not in tree
+	ErrIndexOutOfRange = errors.New("leaf index out of range")
+
+	// ErrInvalidProof - returned when proof does not link leaf to root
+	ErrInvalidProof = errors.New("invalid merkle proof")
+)
+
+// Prove - inclusion proof of leaf at specified index in Merkle tree over specified leaves
+func Prove(leaves []common.Hash, index int) (*Proof, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, ErrIndexOutOfRange
+	}
+
+	proof := &Proof{Index: index, Leaves: len(leaves)}
+
+	level := make([]common.Hash, len(leaves))
+
+	for x, leaf := range leaves {
+		level[x] = hashLeaf(leaf)
+	}
+
+	for x := index; len(level) > 1; x /= 2 {
+		if x%2 == 1 {
+			proof.Siblings = append(proof.Siblings, level[x-1])
+		} else if x+1 < len(level) {
+			proof.Siblings = append(proof.Siblings, level[x+1])
+		}
+
+		level = nextLevel(level)
+	}
+
+	return proof, nil
+}
+
+// Verify - check that proof links specified leaf to specified root
+func (proof *Proof) Verify(root common.Hash, leaf common.Hash) error {
+	if proof == nil || proof.Index < 0 || proof.Index >= proof.Leaves {
+		return ErrInvalidProof
+	}
+
+	node := hashLeaf(leaf)
+	siblings := proof.Siblings
+
+	for x, width := proof.Index, proof.Leaves; width > 1; x, width = x/2, (width+1)/2 {
+		if x%2 == 0 && x+1 == width {
+			continue // Carried up unpaired
+		}
+
+		if len(siblings) == 0 {
+			return ErrInvalidProof
+		}
+
+		if x%2 == 1 {
+			node = hashNode(siblings[0], node)
+		} else {
+			node = hashNode(node, siblings[0])
+		}
+
+		siblings = siblings[1:]
+	}
+
+	if len(siblings) != 0 || node != root {
+		return ErrInvalidProof
+	}
+
+	return nil
+}
+
+// nextLevel - hash pairs of nodes of level into parent level
+func nextLevel(level []common.Hash) []common.Hash {
+	parents := make([]common.Hash, 0, (len(level)+1)/2)
+
+	for x := 0; x < len(level); x += 2 {
+		if x+1 == len(level) {
+			parents = append(parents, level[x])
+			continue
+		}
+
+		parents = append(parents, hashNode(level[x], level[x+1]))
+	}
+
+	return parents
+}
+
+func hashLeaf(leaf common.Hash) common.Hash {
+	return common.BytesToHash(sum([]byte{leafPrefix}, leaf[:]))
+}
+
+func hashNode(left common.Hash, right common.Hash) common.Hash {
+	return common.BytesToHash(sum([]byte{nodePrefix}, left[:], right[:]))
+}
+
+func sum(parts ...[]byte) []byte {
+	hash := sha256.New()
+
+	for _, part := range parts {
+		hash.Write(part)
+	}
+
+	return hash.Sum(nil)
+}
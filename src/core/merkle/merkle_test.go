@@ -0,0 +1,80 @@
+package merkle
+
+import (
+	"testing"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+)
+
+func TestProve(t *testing.T) {
+	var leaves []common.Hash
+
+	for x := 0; x < 7; x++ {
+		leaves = append(leaves, common.BytesToHash([]byte{byte(x + 1)}))
+
+		root := Root(leaves)
+
+		for y := range leaves {
+			proof, err := Prove(leaves, y)
+
+			if err != nil || proof.Verify(root, leaves[y]) != nil {
+				t.Fatalf("proof of leaf %d in tree of %d leaves rejected (%v)", y, len(leaves), err)
+			}
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	var leaves []common.Hash
+
+	for x := 0; x < 5; x++ {
+		leaves = append(leaves, common.BytesToHash([]byte{byte(x + 1)}))
+	}
+
+	root := Root(leaves)
+	proof, _ := Prove(leaves, 2)
+
+	truncated := *proof
+	truncated.Siblings = proof.Siblings[1:]
+
+	extended := *proof
+	extended.Siblings = append(append([]common.Hash{}, proof.Siblings...), leaves[0])
+
+	moved := *proof
+	moved.Index = 3
+
+	tests := []struct {
+		name  string
+		proof *Proof
+		root  common.Hash
+		leaf  common.Hash
+		err   error
+	}{
+		{"valid", proof, root, leaves[2], nil},
+		{"other leaf", proof, root, leaves[1], ErrInvalidProof},
+		{"forged leaf", proof, root, common.Hash{}, ErrInvalidProof},
+		{"other root", proof, Root(leaves[:4]), leaves[2], ErrInvalidProof},
+		{"missing sibling", &truncated, root, leaves[2], ErrInvalidProof},
+		{"extra sibling", &extended, root, leaves[2], ErrInvalidProof},
+		{"other index", &moved, root, leaves[2], ErrInvalidProof},
+		{"nil proof", nil, root, leaves[2], ErrInvalidProof},
+	}
+
+	for _, test := range tests {
+		if err := test.proof.Verify(test.root, test.leaf); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+
+	if _, err := Prove(leaves, len(leaves)); err != ErrIndexOutOfRange {
+		t.Errorf("proof created for leaf out of range (%v)", err)
+	}
+}
+
+func TestRootDomainSeparation(t *testing.T) {
+	leaves := []common.Hash{common.BytesToHash([]byte{0x01}), common.BytesToHash([]byte{0x02})}
+
+	if Root(leaves) == Root([]common.Hash{Root(leaves)}) {
+		t.Errorf("interior node indistinguishable from leaf")
+	}
+}
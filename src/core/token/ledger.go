@@ -0,0 +1,275 @@
+package token
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/core/types"
+)
+
+var (
+	// ErrNotCreated - returned when applying token operation before token creation
+	ErrNotCreated = errors.New("token not yet created")
+
+	// ErrAlreadyCreated - returned when token creation appears more than once in token chain
+	ErrAlreadyCreated = errors.New("token already created")
+
+	// ErrNotIssuer - returned when account other than issuer attempts to mint
+	ErrNotIssuer = errors.New("only token issuer may mint")
+
+	// ErrInsufficientBalance - returned when transfer exceeds sender's balance
+	ErrInsufficientBalance = errors.New("insufficient token balance")
+
+	// ErrStaleLedger - returned when updating ledger with chain that does not extend state ledger was derived from
+	ErrStaleLedger = errors.New("chain does not extend ledger state")
+)
+
+// Ledger - token state derived from token chain: token parameters, supply & per-account balances
+type Ledger struct {
+	Token    *Token                  `json:"token"`
+	Supply   types.Amount            `json:"supply"`
+	Balances map[string]types.Amount `json:"balances"` // Keyed by hex account address
+
+	Version int `json:"version"` // Chain version of last applied transaction
+
+	last common.Hash // Hash of last applied transaction (zero if state was restored from snapshot)
+}
+
+// NewLedger - initialize empty ledger for token with specified identifier
+func NewLedger(id common.Identifier) *Ledger {
+	return &Ledger{Token: &Token{ID: id}, Balances: make(map[string]types.Amount)}
+}
+
+// LedgerFromChain - replay token chain, returning resulting token state; chains holding snapshot are
+// replayed from snapshot onward
+func LedgerFromChain(ch *types.Chain) (*Ledger, error) {
+	if !IsTokenChain(ch) {
+		return nil, errors.New("chain is not token chain")
+	}
+
+	ledger := NewLedger(ch.Identifier)
+
+	if ch.Snapshot != nil {
+		err := ledger.restore(ch.Snapshot)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, tx := range ch.Transactions {
+		if tx != nil && ch.Snapshot != nil && tx.ChainVersion <= ch.Snapshot.Version {
+			continue // Reflected in snapshot
+		}
+
+		err := ledger.Apply(tx)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ledger, nil
+}
+
+// Update - apply transactions added to token chain since ledger's version; ErrStaleLedger is returned if
+// chain no longer extends ledger state (e.g. chain replaced or pruned past ledger), in which case ledger
+// must be rebuilt with LedgerFromChain
+func (ledger *Ledger) Update(ch *types.Chain) error {
+	if !ch.Identifier.Equal(ledger.Token.ID) || ledger.Version > ch.Version {
+		return ErrStaleLedger
+	}
+
+	x := sort.Search(len(ch.Transactions), func(i int) bool {
+		return ch.Transactions[i].ChainVersion > ledger.Version
+	})
+
+	if x > 0 {
+		if prev := ch.Transactions[x-1]; prev.ChainVersion != ledger.Version || prev.Hash() != ledger.last {
+			return ErrStaleLedger
+		}
+	} else if (ch.Snapshot == nil && ledger.Version != 0) || (ch.Snapshot != nil && ch.Snapshot.Version != ledger.Version) {
+		return ErrStaleLedger // Transactions preceding ledger state not held by chain
+	}
+
+	for _, tx := range ch.Transactions[x:] {
+		err := ledger.Apply(tx)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SnapshotData - token parameters carried in snapshots of token chain
+func SnapshotData(ch *types.Chain) ([]byte, error) {
+	ledger, err := LedgerFromChain(ch)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(ledger.Token)
+}
+
+// restore - reset ledger to token state recorded in snapshot of token chain
+func (ledger *Ledger) restore(s *types.Snapshot) error {
+	token := &Token{}
+	err := json.Unmarshal(s.Data, token)
+
+	if err != nil {
+		return err
+	}
+
+	if !token.ID.Equal(ledger.Token.ID) {
+		return errors.New("snapshot of other token")
+	}
+
+	ledger.Token = token
+	ledger.Supply = types.Amount{}
+	ledger.Balances = make(map[string]types.Amount)
+	ledger.Version = s.Version
+	ledger.last = common.Hash{}
+
+	for x := range s.Accounts {
+		balance, err := s.Accounts[x].Balance()
+
+		if err != nil {
+			return err
+		}
+
+		if balance.IsZero() {
+			continue
+		}
+
+		ledger.Balances[hex.EncodeToString(s.Accounts[x].Address[:])] = balance
+
+		ledger.Supply, err = ledger.Supply.Add(balance)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// BalanceOf - token balance of specified account
+func (ledger *Ledger) BalanceOf(addr common.Address) types.Amount {
+	return ledger.Balances[hex.EncodeToString(addr[:])]
+}
+
+// Check - check if transaction is valid token operation given current state, without applying it
+func (ledger *Ledger) Check(tx *types.Transaction) error {
+	_, err := ledger.check(tx)
+	return err
+}
+
+// Apply - apply token operation carried by transaction to ledger
+func (ledger *Ledger) Apply(tx *types.Transaction) error {
+	op, err := ledger.check(tx)
+
+	if err != nil {
+		return err
+	}
+
+	amount := tx.Data.Amount
+	to := hex.EncodeToString(tx.Data.Recipient[:])
+
+	switch op.Op {
+	case OpCreate:
+		ledger.Token = op.Token
+		ledger.Supply = amount
+		ledger.Balances[to] = amount
+	case OpMint:
+		ledger.Supply, _ = ledger.Supply.Add(amount) // Overflow ruled out by check
+		ledger.Balances[to], _ = ledger.Balances[to].Add(amount)
+	case OpTransfer:
+		from := hex.EncodeToString(tx.SendingAccount.Address[:])
+
+		ledger.Balances[from], _ = ledger.Balances[from].Sub(amount)
+		ledger.Balances[to], _ = ledger.Balances[to].Add(amount)
+
+		if ledger.Balances[from].IsZero() {
+			delete(ledger.Balances, from)
+		}
+	}
+
+	if tx.ChainVersion > ledger.Version {
+		ledger.Version = tx.ChainVersion
+		ledger.last = tx.Hash()
+	}
+
+	return nil
+}
+
+// check - validate token operation against ledger, returning decoded operation; operation must be
+// signed by its sender
+func (ledger *Ledger) check(tx *types.Transaction) (*Operation, error) {
+	if reflect.ValueOf(tx).IsNil() {
+		return nil, errors.New("invalid token transaction: nil")
+	}
+
+	err := tx.Verify()
+
+	if err != nil {
+		return nil, err // Balances & issuer rights belong to authenticated sender only
+	}
+
+	op, err := DecodeOperation(tx, ledger.Token.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tx.Data.Recipient == nil {
+		return nil, errors.New("invalid token transaction: missing recipient")
+	}
+
+	created := ledger.Token.Name != ""
+
+	switch op.Op {
+	case OpCreate:
+		if created {
+			return nil, ErrAlreadyCreated
+		}
+
+		if op.Token == nil || op.Token.Validate() != nil || !op.Token.identifier().Equal(ledger.Token.ID) || !op.Token.ID.Equal(ledger.Token.ID) {
+			return nil, ErrInvalidToken
+		}
+
+		if tx.SendingAccount.Address != op.Token.Issuer || op.Token.Issuer != *tx.Data.Recipient || tx.Data.Amount.Cmp(op.Token.Supply) != 0 {
+			return nil, errors.New("invalid token creation: supply must be credited to issuer")
+		}
+	case OpMint:
+		if !created {
+			return nil, ErrNotCreated
+		}
+
+		if tx.SendingAccount.Address != ledger.Token.Issuer {
+			return nil, ErrNotIssuer
+		}
+
+		if _, err := ledger.Supply.Add(tx.Data.Amount); err != nil {
+			return nil, err
+		}
+	case OpTransfer:
+		if !created {
+			return nil, ErrNotCreated
+		}
+
+		if ledger.BalanceOf(tx.SendingAccount.Address).Cmp(tx.Data.Amount) < 0 {
+			return nil, ErrInsufficientBalance
+		}
+	default:
+		return nil, errors.New("unknown token operation " + op.Op)
+	}
+
+	return op, nil
+}
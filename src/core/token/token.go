@@ -0,0 +1,188 @@
+package token
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/contracts"
+	"github.com/mitsukomegumi/indo-go/src/core/types"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+)
+
+const (
+	// MaxNameLength - maximum length of token name
+	MaxNameLength = 64
+
+	// MaxSymbolLength - maximum length of token symbol
+	MaxSymbolLength = 12
+)
+
+// Operation types carried in payload of token transactions
+const (
+	OpCreate   = "create"
+	OpMint     = "mint"
+	OpTransfer = "transfer"
+)
+
+var (
+	// ErrInvalidToken - returned when token parameters are invalid
+	ErrInvalidToken = errors.New("invalid token")
+
+	// ErrNotTokenTx - returned when transaction does not reference token
+	ErrNotTokenTx = errors.New("transaction does not reference token")
+)
+
+// Token - asset issued on token hosting platform; each token lives on its own chain, whose
+// identifier is the token's identifier
+type Token struct {
+	ID common.Identifier `json:"id"`
+
+	Name   string         `json:"name"`
+	Symbol string         `json:"symbol"`
+	Supply types.Amount   `json:"supply"` // Amount credited to issuer on creation
+	Issuer common.Address `json:"issuer"` // Only account permitted to mint
+
+	Created time.Time `json:"created"`
+}
+
+// Operation - token operation carried in transaction payload
+type Operation struct {
+	Op    string `json:"op"`
+	Token *Token `json:"token,omitempty"` // Set on creation only
+}
+
+// NewToken - initialize token with specified name, symbol, initial supply & issuer
+func NewToken(name string, symbol string, supply types.Amount, issuer common.Address) (*Token, error) {
+	token := &Token{Name: name, Symbol: strings.ToUpper(symbol), Supply: supply, Issuer: issuer, Created: time.Now().UTC()}
+
+	err := token.Validate()
+
+	if err != nil {
+		return nil, err
+	}
+
+	token.ID = token.identifier()
+
+	return token, nil
+}
+
+// Validate - check token parameters
+func (token *Token) Validate() error {
+	if token.Name == "" || len(token.Name) > MaxNameLength {
+		return errors.New("invalid token: name must be between 1 & 64 characters")
+	}
+
+	if token.Symbol == "" || len(token.Symbol) > MaxSymbolLength || strings.ContainsAny(token.Symbol, " \t\n") {
+		return errors.New("invalid token: symbol must be between 1 & 12 characters without whitespace")
+	}
+
+	if token.Issuer == (common.Address{}) {
+		return errors.New("invalid token: missing issuer")
+	}
+
+	return nil
+}
+
+// identifier - hash of token parameters
+func (token *Token) identifier() common.Identifier {
+	params := Token{Name: token.Name, Symbol: token.Symbol, Supply: token.Supply, Issuer: token.Issuer, Created: token.Created}
+	b, _ := json.Marshal(params)
+	sum := sha256.Sum256(b)
+
+	return common.Identifier(sum[:])
+}
+
+// Contract - parent contract anchoring token chain; transactions reference token through it
+func (token *Token) Contract() *contracts.Contract {
+	return &contracts.Contract{Identifier: []byte(token.ID)}
+}
+
+// NewChain - initialize token chain holding token creation transaction, credited to issuer & signed
+// with sign (e.g. wallet.SignTx)
+func (token *Token) NewChain(issuer types.Account, sign func(*types.Transaction) error, Db *discovery.NodeDatabase) (*types.Chain, error) {
+	tx, err := token.CreateTransaction(issuer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = sign(tx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ch := &types.Chain{ParentContract: token.Contract(), Identifier: token.ID, NodeDb: Db}
+	ch.AddTransaction(tx)
+
+	return ch, nil
+}
+
+// CreateTransaction - transaction creating token, crediting initial supply to issuer
+func (token *Token) CreateTransaction(issuer types.Account) (*types.Transaction, error) {
+	if issuer.Address != token.Issuer {
+		return nil, errors.New("token must be created by issuer")
+	}
+
+	return token.newTransaction(0, issuer, token.Issuer, token.Supply, Operation{Op: OpCreate, Token: token})
+}
+
+// MintTransaction - transaction issuing amount of new tokens to recipient; only valid if sent by issuer
+func (token *Token) MintTransaction(nonce uint64, issuer types.Account, to common.Address, amount types.Amount) (*types.Transaction, error) {
+	return token.newTransaction(nonce, issuer, to, amount, Operation{Op: OpMint})
+}
+
+// TransferTransaction - transaction moving amount of tokens from sender to recipient
+func (token *Token) TransferTransaction(nonce uint64, from types.Account, to common.Address, amount types.Amount) (*types.Transaction, error) {
+	return token.newTransaction(nonce, from, to, amount, Operation{Op: OpTransfer})
+}
+
+func (token *Token) newTransaction(nonce uint64, from types.Account, to common.Address, amount types.Amount, op Operation) (*types.Transaction, error) {
+	payload, err := json.Marshal(op)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return types.NewTransaction(nonce, from, to, amount, payload, token.Contract(), nil), nil
+}
+
+// DecodeOperation - token operation carried by transaction referencing token with specified identifier
+func DecodeOperation(tx *types.Transaction, id common.Identifier) (*Operation, error) {
+	if tx.Contract == nil || !common.Identifier(tx.Contract.Identifier).Equal(id) {
+		return nil, ErrNotTokenTx
+	}
+
+	op := Operation{}
+	err := json.NewDecoder(bytes.NewReader(tx.Data.Payload)).Decode(&op)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &op, nil
+}
+
+// Issuance - reports transactions of token with specified identifier that credit recipient without
+// debiting sender (token creation & minting)
+func Issuance(id common.Identifier) types.IssuanceFunc {
+	return func(tx *types.Transaction) bool {
+		if len(id) == 0 {
+			return false
+		}
+
+		op, err := DecodeOperation(tx, id)
+
+		return err == nil && op.Op != OpTransfer
+	}
+}
+
+// IsTokenChain - check if chain is token chain, anchored to parent contract carrying chain identifier
+func IsTokenChain(ch *types.Chain) bool {
+	return ch != nil && len(ch.Identifier) != 0 && ch.ParentContract != nil && common.Identifier(ch.ParentContract.Identifier).Equal(ch.Identifier)
+}
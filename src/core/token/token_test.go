@@ -0,0 +1,199 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/mitsukomegumi/indo-go/src/core/types"
+)
+
+// testAccount - fresh account & its key
+func testAccount(t *testing.T) (*types.Account, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return types.NewAccount(types.PubkeyToAddress(&key.PublicKey)), key
+}
+
+// signed - sign token transaction with sender's key
+func signed(t *testing.T, tx *types.Transaction, err error, key *ecdsa.PrivateKey) *types.Transaction {
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.SignWith(key); err != nil {
+		t.Fatal(err)
+	}
+
+	return tx
+}
+
+// signer - signing function of key
+func signer(key *ecdsa.PrivateKey) func(*types.Transaction) error {
+	return func(tx *types.Transaction) error {
+		return tx.SignWith(key)
+	}
+}
+
+func TestToken(t *testing.T) {
+	issuer, issuerKey := testAccount(t)
+	holder, holderKey := testAccount(t)
+
+	tok, err := NewToken("Creator Fund", "fund", types.NewAmount(1000), issuer.Address)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch, err := tok.NewChain(*issuer, signer(issuerKey), nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !IsTokenChain(ch) || !ch.Identifier.Equal(tok.ID) {
+		t.Fatalf("token chain not anchored to token contract")
+	}
+
+	transfer, err := tok.TransferTransaction(1, *issuer, holder.Address, types.NewAmount(400))
+	ch.AddTransaction(signed(t, transfer, err, issuerKey))
+
+	mint, err := tok.MintTransaction(2, *issuer, holder.Address, types.NewAmount(50))
+	ch.AddTransaction(signed(t, mint, err, issuerKey))
+
+	ledger, err := LedgerFromChain(ch)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ledger.Token.Symbol != "FUND" || ledger.Supply.String() != "1050" || ledger.BalanceOf(issuer.Address).String() != "600" || ledger.BalanceOf(holder.Address).String() != "450" {
+		t.Errorf("unexpected token state: supply %s, balances %v", ledger.Supply, ledger.Balances)
+	}
+
+	other, _ := NewToken("Other", "OTH", types.NewAmount(1), issuer.Address)
+
+	overdraw, err := tok.TransferTransaction(1, *holder, issuer.Address, types.NewAmount(451))
+	overdraw = signed(t, overdraw, err, holderKey)
+
+	forged, err := tok.MintTransaction(2, *holder, holder.Address, types.NewAmount(1))
+	forged = signed(t, forged, err, holderKey)
+
+	foreign, err := other.TransferTransaction(3, *issuer, holder.Address, types.NewAmount(1))
+	foreign = signed(t, foreign, err, issuerKey)
+
+	recreate, err := tok.CreateTransaction(*issuer)
+	recreate = signed(t, recreate, err, issuerKey)
+
+	unsigned, _ := tok.MintTransaction(3, *issuer, holder.Address, types.NewAmount(1))
+
+	stolen, err := tok.TransferTransaction(3, *issuer, holder.Address, types.NewAmount(1))
+	stolen = signed(t, stolen, err, issuerKey)
+	stolen.SendingAccount = *holder // Holder replays issuer's signature as own transfer
+
+	tests := []struct {
+		name string
+		tx   *types.Transaction
+		err  error
+	}{
+		{"overdraw", overdraw, ErrInsufficientBalance},
+		{"mint by non-issuer", forged, ErrNotIssuer},
+		{"other token", foreign, ErrNotTokenTx},
+		{"second creation", recreate, ErrAlreadyCreated},
+		{"unsigned mint by issuer", unsigned, types.ErrUnsigned},
+		{"forged sender", stolen, types.ErrHashMismatch},
+	}
+
+	for _, test := range tests {
+		if err := ledger.Check(test.tx); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestNewChainUnsigned(t *testing.T) {
+	issuer, _ := testAccount(t)
+
+	tok, err := NewToken("Creator Fund", "fund", types.NewAmount(1000), issuer.Address)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch, err := tok.NewChain(*issuer, func(*types.Transaction) error { return nil }, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LedgerFromChain(ch); err != types.ErrUnsigned {
+		t.Errorf("token chain with unsigned creation replayed (%v)", err)
+	}
+}
+
+func TestLedgerUpdate(t *testing.T) {
+	issuer, issuerKey := testAccount(t)
+	holder, _ := testAccount(t)
+
+	tok, err := NewToken("Creator Fund", "fund", types.NewAmount(1000), issuer.Address)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch, err := tok.NewChain(*issuer, signer(issuerKey), nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ledger, err := LedgerFromChain(ch)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := *ch // Chain as of ledger's state
+
+	transfer, err := tok.TransferTransaction(1, *issuer, holder.Address, types.NewAmount(400))
+	ch.AddTransaction(signed(t, transfer, err, issuerKey))
+
+	if err := ledger.Update(ch); err != nil || ledger.BalanceOf(holder.Address).String() != "400" || ledger.Version != ch.Version {
+		t.Fatalf("ledger not updated with transfer: balance %s at version %d (%v)", ledger.BalanceOf(holder.Address), ledger.Version, err)
+	}
+
+	// Diverging chain holding different transaction at ledger's version
+	diverged := base
+	diverged.Transactions = base.Transactions[:len(base.Transactions):len(base.Transactions)]
+	mint, err := tok.MintTransaction(1, *issuer, holder.Address, types.NewAmount(50))
+	diverged.AddTransaction(signed(t, mint, err, issuerKey))
+
+	other, _ := NewToken("Other", "OTH", types.NewAmount(1), issuer.Address)
+	otherCh, err := other.NewChain(*issuer, signer(issuerKey), nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		ch   *types.Chain
+		err  error
+	}{
+		{"up to date", ch, nil},
+		{"chain behind ledger", &base, ErrStaleLedger},
+		{"diverged chain", &diverged, ErrStaleLedger},
+		{"other token", otherCh, ErrStaleLedger},
+	}
+
+	for _, test := range tests {
+		if err := ledger.Update(test.ch); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
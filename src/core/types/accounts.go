@@ -12,9 +12,8 @@ type Account struct {
 }
 
 // GetBalance - returns balance of specified account.
-func GetBalance(account Account) *int {
-	test := 100
-	return &test
+func GetBalance(account Account) Amount {
+	return NewAmount(100)
 }
 
 // NewAccount - return new account
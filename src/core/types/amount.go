@@ -0,0 +1,189 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// AmountDecimals - number of decimal places amounts are divisible to; amounts are held as whole
+// numbers of base units, 10^AmountDecimals of which make up one coin
+const AmountDecimals = 8
+
+var (
+	// ErrNegativeAmount - returned when operation would produce negative amount
+	ErrNegativeAmount = errors.New("amount must not be negative")
+
+	// ErrAmountOverflow - returned when operation would produce amount above MaxAmount
+	ErrAmountOverflow = errors.New("amount exceeds maximum amount")
+
+	// ErrInvalidAmount - returned when decoding malformed amount
+	ErrInvalidAmount = errors.New("invalid amount")
+)
+
+var (
+	unitsPerCoin = new(big.Int).Exp(big.NewInt(10), big.NewInt(AmountDecimals), nil)
+
+	// maxUnits - largest representable number of base units (2^256 - 1)
+	maxUnits = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+)
+
+// Amount - non-negative quantity of value with AmountDecimals decimal places; the zero value is zero.
+// Amounts are immutable: arithmetic returns new amounts.
+type Amount struct {
+	units *big.Int
+}
+
+// MaxAmount - largest representable amount
+func MaxAmount() Amount {
+	return Amount{units: new(big.Int).Set(maxUnits)}
+}
+
+// NewAmount - amount of specified number of whole coins
+func NewAmount(coins uint64) Amount {
+	units := new(big.Int).SetUint64(coins)
+	return Amount{units: units.Mul(units, unitsPerCoin)}
+}
+
+// AmountFromUnits - amount of specified number of base units
+func AmountFromUnits(units *big.Int) (Amount, error) {
+	return checkedAmount(new(big.Int).Set(units))
+}
+
+// ParseAmount - parse decimal amount (e.g. "12.5"), rejecting negative amounts & excess decimal places
+func ParseAmount(s string) (Amount, error) {
+	whole, frac := s, ""
+
+	if x := strings.Index(s, "."); x >= 0 {
+		whole, frac = s[:x], s[x+1:]
+	}
+
+	if (whole == "" && frac == "") || len(frac) > AmountDecimals || strings.ContainsAny(whole+frac, "+- _") {
+		return Amount{}, ErrInvalidAmount
+	}
+
+	digits := whole + frac + strings.Repeat("0", AmountDecimals-len(frac))
+
+	units, ok := new(big.Int).SetString(digits, 10)
+
+	if !ok {
+		return Amount{}, ErrInvalidAmount
+	}
+
+	return checkedAmount(units)
+}
+
+// checkedAmount - wrap base units in amount if within range
+func checkedAmount(units *big.Int) (Amount, error) {
+	if units.Sign() < 0 {
+		return Amount{}, ErrNegativeAmount
+	}
+
+	if units.Cmp(maxUnits) > 0 {
+		return Amount{}, ErrAmountOverflow
+	}
+
+	return Amount{units: units}, nil
+}
+
+// int - base units of amount; must not be modified
+func (a Amount) int() *big.Int {
+	if a.units == nil {
+		return new(big.Int)
+	}
+	return a.units
+}
+
+// Units - copy of number of base units making up amount
+func (a Amount) Units() *big.Int {
+	return new(big.Int).Set(a.int())
+}
+
+// Add - sum of amounts, failing if sum exceeds MaxAmount
+func (a Amount) Add(b Amount) (Amount, error) {
+	return checkedAmount(new(big.Int).Add(a.int(), b.int()))
+}
+
+// Sub - difference of amounts, failing if b is greater than a
+func (a Amount) Sub(b Amount) (Amount, error) {
+	return checkedAmount(new(big.Int).Sub(a.int(), b.int()))
+}
+
+// Cmp - compare amounts, returning -1 if a < b, 0 if a == b & +1 if a > b
+func (a Amount) Cmp(b Amount) int {
+	return a.int().Cmp(b.int())
+}
+
+// IsZero - check if amount is zero
+func (a Amount) IsZero() bool {
+	return a.int().Sign() == 0
+}
+
+// String - canonical decimal representation of amount, without trailing zeros (e.g. "12.5")
+func (a Amount) String() string {
+	whole, frac := new(big.Int).QuoRem(a.int(), unitsPerCoin, new(big.Int))
+
+	if frac.Sign() == 0 {
+		return whole.String()
+	}
+
+	fracStr := frac.String()
+	fracStr = strings.Repeat("0", AmountDecimals-len(fracStr)) + fracStr
+
+	return whole.String() + "." + strings.TrimRight(fracStr, "0")
+}
+
+// MarshalJSON - encode amount as canonical decimal string
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON - decode amount from decimal string or number
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	s := strings.TrimSpace(string(b))
+
+	if s == "null" {
+		*a = Amount{}
+		return nil
+	}
+
+	if strings.HasPrefix(s, "\"") {
+		err := json.Unmarshal(b, &s)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	parsed, err := ParseAmount(s)
+
+	if err != nil {
+		return err
+	}
+
+	*a = parsed
+
+	return nil
+}
+
+// MarshalBinary - encode amount as big-endian base units
+func (a Amount) MarshalBinary() ([]byte, error) {
+	return a.int().Bytes(), nil
+}
+
+// UnmarshalBinary - decode amount from big-endian base units, rejecting non-minimal encodings
+// (leading zero bytes; zero is encoded as no bytes) so each amount has a single binary encoding
+func (a *Amount) UnmarshalBinary(b []byte) error {
+	if len(b) > 32 {
+		return ErrAmountOverflow
+	}
+
+	if len(b) > 0 && b[0] == 0 {
+		return ErrInvalidAmount
+	}
+
+	*a = Amount{units: new(big.Int).SetBytes(b)}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		input string
+		units string // Empty if invalid
+	}{
+		{"12.5", "1250000000"},
+		{"0", "0"},
+		{"1.12345678", "112345678"},
+		{"", ""},
+		{"-1", ""},
+		{"1.123456789", ""},
+		{"1e3", ""},
+		{"abc", ""},
+		{".", ""},
+	}
+
+	for _, test := range tests {
+		a, err := ParseAmount(test.input)
+
+		if (err == nil) != (test.units != "") {
+			t.Errorf("%q: expected valid %v, got %v", test.input, test.units != "", err)
+		} else if err == nil && a.Units().String() != test.units {
+			t.Errorf("%q: expected %s units, got %s", test.input, test.units, a.Units())
+		}
+	}
+}
+
+func TestAmountArithmetic(t *testing.T) {
+	a, _ := ParseAmount("12.5")
+
+	if sum, err := a.Add(NewAmount(1)); err != nil || sum.String() != "13.5" {
+		t.Errorf("unexpected sum %s", sum)
+	}
+
+	if _, err := NewAmount(1).Sub(a); err != ErrNegativeAmount {
+		t.Errorf("expected negative amount error, got %v", err)
+	}
+
+	if _, err := MaxAmount().Add(NewAmount(1)); err != ErrAmountOverflow {
+		t.Errorf("expected overflow error, got %v", err)
+	}
+}
+
+func TestAmountEncoding(t *testing.T) {
+	a, _ := ParseAmount("12.5")
+
+	b, err := json.Marshal(a)
+
+	if err != nil || string(b) != `"12.5"` {
+		t.Errorf("unexpected json encoding %s", b)
+	}
+
+	var decoded Amount
+
+	if err := json.Unmarshal([]byte("7"), &decoded); err != nil || decoded.Cmp(NewAmount(7)) != 0 {
+		t.Errorf("numeric json amount not decoded (%v)", err)
+	}
+
+	bin, _ := MaxAmount().MarshalBinary()
+
+	if err := decoded.UnmarshalBinary(bin); err != nil || decoded.Cmp(MaxAmount()) != 0 {
+		t.Errorf("binary encoding not canonical (%v)", err)
+	}
+
+	tests := []struct {
+		name string
+		b    []byte
+		err  error
+	}{
+		{"zero", nil, nil},
+		{"minimal", []byte{0x01, 0x00}, nil},
+		{"leading zero", []byte{0x00, 0x01}, ErrInvalidAmount},
+		{"zero as byte", []byte{0x00}, ErrInvalidAmount},
+		{"overflow", make([]byte, 33), ErrAmountOverflow},
+	}
+
+	for _, test := range tests {
+		if err := decoded.UnmarshalBinary(test.b); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
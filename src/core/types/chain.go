@@ -3,8 +3,10 @@ package types
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/mitsukomegumi/indo-go/src/common"
 	"github.com/mitsukomegumi/indo-go/src/contracts"
@@ -14,13 +16,19 @@ import (
 // Chain - Connected collection of transactions
 type Chain struct {
 	ParentContract *contracts.Contract `json:"parentcontract"`
-	Identifier     Identifier          `json:"identifier"`
+	Identifier     common.Identifier   `json:"identifier"`
 
 	NodeDb *discovery.NodeDatabase `json:"database"`
 
 	Transactions []*Transaction `json:"transactions"`
 
 	Version int `json:"version"`
+
+	Checkpoints []*Header `json:"checkpoints"` // Signed checkpoints over transaction history, oldest first
+
+	Snapshot *Snapshot `json:"snapshot,omitempty"` // Latest state snapshot; covered transactions may be pruned
+
+	SpamPolicy SpamPolicy `json:"spampolicy"` // Proof-of-work & rate limits required of transactions
 }
 
 // AddTransaction - Add transaction to specified chain object
@@ -39,6 +47,77 @@ func (RefChain *Chain) AddTransaction(Transaction *Transaction) {
 	fmt.Println("transaction added to chain")
 }
 
+// HasTransaction - check if transaction with specified hash has been added to chain
+func (RefChain Chain) HasTransaction(hash common.Hash) bool {
+	for _, tx := range RefChain.Transactions {
+		if tx != nil && tx.Hash() == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// LatestWitnessTime - return witness time of most recently witnessed transaction in chain (zero if none)
+func (RefChain Chain) LatestWitnessTime() time.Time {
+	for x := len(RefChain.Transactions) - 1; x >= 0; x-- {
+		tx := RefChain.Transactions[x]
+
+		if tx != nil && tx.InitialWitness != nil {
+			return tx.InitialWitness.WitnessTime
+		}
+	}
+	return time.Time{}
+}
+
+// TransactionsSince - return at most limit transactions added to chain after specified chain version, in version order
+func (RefChain Chain) TransactionsSince(Version int, Limit int) []*Transaction {
+	var Transactions []*Transaction
+
+	for _, tx := range RefChain.Transactions {
+		if len(Transactions) == Limit {
+			break
+		}
+
+		if tx.ChainVersion > Version {
+			Transactions = append(Transactions, tx)
+		}
+	}
+
+	return Transactions
+}
+
+// ValidateBatch - check that specified transactions directly extend chain, are signed by their senders
+// & match checkpoints already known to chain, without modifying chain
+func (RefChain Chain) ValidateBatch(Transactions []*Transaction) error {
+	expectedVersion := RefChain.Version + 1
+
+	for _, tx := range Transactions {
+		if reflect.ValueOf(tx).IsNil() {
+			return errors.New("invalid batch: nil transaction")
+		}
+
+		if tx.Data.InitialHash == nil {
+			return errors.New("invalid batch: transaction missing hash")
+		}
+
+		if err := tx.Verify(); err != nil {
+			return fmt.Errorf("invalid batch: transaction with chain version %d: %s", tx.ChainVersion, err.Error())
+		}
+
+		if reflect.ValueOf(tx.InitialWitness).IsNil() {
+			return errors.New("invalid batch: transaction not witnessed")
+		}
+
+		if tx.ChainVersion != expectedVersion {
+			return fmt.Errorf("invalid batch: expected transaction with chain version %d, found %d", expectedVersion, tx.ChainVersion)
+		}
+
+		expectedVersion++
+	}
+
+	return RefChain.verifyKnownCheckpoints(Transactions)
+}
+
 // FindUnverifiedTransactions - Browse chain for most recent unverified transactions
 func (RefChain Chain) FindUnverifiedTransactions(TxCount int) []*Transaction {
 
@@ -59,10 +138,21 @@ func (RefChain Chain) FindUnverifiedTransactions(TxCount int) []*Transaction {
 	return UnverifiedTransactions
 }
 
+// ChainPath - file chain with specified identifier is stored in under specified path; the
+// default chain (empty identifier) is stored in path+"Chain.gob"
+func ChainPath(path string, id common.Identifier) string {
+	return path + id.String() + "Chain.gob"
+}
+
 // WriteChainToMemory - create serialized instance of specified chain in specified path (string)
 func (RefChain Chain) WriteChainToMemory(path string) error {
-	common.WriteGob(path+string(RefChain.Identifier)+"Chain.gob", RefChain)
-	err := RefChain.NodeDb.WriteDbToMemory(common.GetCurrentDir())
+	err := common.WriteGob(ChainPath(path, RefChain.Identifier), RefChain)
+
+	if err != nil {
+		return err
+	}
+
+	err = RefChain.NodeDb.WriteDbToMemory(common.GetCurrentDir())
 
 	if err != nil {
 		return err
@@ -70,17 +160,158 @@ func (RefChain Chain) WriteChainToMemory(path string) error {
 	return nil
 }
 
-// ReadChainFromMemory - read serialized object of specified chain from specified path
+// legacyChain - chain format holding node database & transactions in legacy format; only used for migration.
+// Identifiers, hashes & addresses are held as plain byte slices & arrays, as gob only decodes legacy encodings
+// of those into types without binary marshalers.
+type legacyChain struct {
+	ParentContract *contracts.Contract
+	Identifier     []byte
+	NodeDb         *discovery.LegacyNodeDatabase
+	Transactions   []*legacyTransaction
+	Version        int
+}
+
+// legacyTransaction - transaction format preceding Amount (amounts held as *int whole coins); only used for migration
+type legacyTransaction struct {
+	Data           legacyTransactionData
+	Contract       *contracts.Contract
+	Verifications  int
+	Weight         int
+	InitialWitness *Witness
+	SendingAccount legacyAccount
+	ChainVersion   int
+}
+
+type legacyTransactionData struct {
+	Nonce       uint64
+	Recipient   *[common.AddressLength]byte
+	Amount      *int
+	Payload     []byte
+	Time        time.Time
+	Extra       []byte
+	InitialHash *[common.HashLength]byte
+	ParentHash  *[common.HashLength]byte
+}
+
+// legacyAccount - account format holding legacy transactions; only used for migration
+type legacyAccount struct {
+	Address      [common.AddressLength]byte
+	URL          URL
+	Transactions []*legacyTransaction
+}
+
+// upgrade - migrate legacy chain into current format
+func (legacy *legacyChain) upgrade() (*Chain, error) {
+	ch := &Chain{ParentContract: legacy.ParentContract, Version: legacy.Version}
+
+	if len(legacy.Identifier) > 0 {
+		ch.Identifier = common.Identifier(legacy.Identifier)
+	}
+
+	if legacy.NodeDb != nil {
+		ch.NodeDb = legacy.NodeDb.Upgrade()
+	}
+
+	txs, err := upgradeTransactions(legacy.Transactions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ch.Transactions = txs
+
+	return ch, nil
+}
+
+// upgradeTransactions - migrate legacy transactions into current format; legacy amounts count whole coins
+func upgradeTransactions(legacy []*legacyTransaction) ([]*Transaction, error) {
+	var txs []*Transaction
+
+	for _, old := range legacy {
+		if old == nil {
+			continue
+		}
+
+		amount := Amount{}
+
+		if old.Data.Amount != nil {
+			if *old.Data.Amount < 0 {
+				return nil, ErrNegativeAmount
+			}
+
+			amount = NewAmount(uint64(*old.Data.Amount))
+		}
+
+		accountTxs, err := upgradeTransactions(old.SendingAccount.Transactions)
+
+		if err != nil {
+			return nil, err
+		}
+
+		tx := &Transaction{
+			Data: transactiondata{
+				Nonce:   old.Data.Nonce,
+				Amount:  amount,
+				Payload: old.Data.Payload,
+				Time:    old.Data.Time,
+				Extra:   old.Data.Extra,
+			},
+			Contract:       old.Contract,
+			Verifications:  old.Verifications,
+			Weight:         old.Weight,
+			InitialWitness: old.InitialWitness,
+			SendingAccount: Account{Address: common.Address(old.SendingAccount.Address), URL: old.SendingAccount.URL, Transactions: accountTxs},
+			ChainVersion:   old.ChainVersion,
+		}
+
+		if old.Data.Recipient != nil {
+			recipient := common.Address(*old.Data.Recipient)
+			tx.Data.Recipient = &recipient
+		}
+
+		if old.Data.InitialHash != nil {
+			hash := common.Hash(*old.Data.InitialHash)
+			tx.Data.InitialHash = &hash
+		}
+
+		if old.Data.ParentHash != nil {
+			parent := common.Hash(*old.Data.ParentHash)
+			tx.Data.ParentHash = &parent
+		}
+
+		txs = append(txs, tx)
+	}
+
+	return txs, nil
+}
+
+// ReadChainFromMemory - read serialized object of default chain from specified path
 func ReadChainFromMemory(path string) *Chain {
+	return ReadChainWithIdentifier(path, nil)
+}
+
+// ReadChainWithIdentifier - read serialized object of chain with specified identifier from specified path
+func ReadChainWithIdentifier(path string, id common.Identifier) *Chain {
 	tempChain := new(Chain)
 
-	error := common.ReadGob(path+"Chain.gob", tempChain)
+	error := common.ReadGob(ChainPath(path, id), tempChain)
+	if error != nil || tempChain.NodeDb == nil || tempChain.NodeDb.Len() == 0 {
+		legacy := new(legacyChain)
+
+		// Legacy chains fail to decode into current format (legacy field types differ), or decode with
+		// legacy node database silently skipped
+		if common.ReadGob(ChainPath(path, id), legacy) == nil && (error != nil || (legacy.NodeDb != nil && legacy.NodeDb.IsLegacy())) {
+			common.ThrowWarning("migrating chain from legacy format")
+
+			tempChain, error = legacy.upgrade()
+		}
+	}
+
 	if error != nil {
 		fmt.Println(error)
-	} else {
-		return tempChain
+		return nil
 	}
-	return nil
+	return tempChain
 }
 
 // DecodeChainFromBytes - decode chain from specified byte array, returning new chain
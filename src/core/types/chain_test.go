@@ -0,0 +1,204 @@
+package types
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"os"
+	"testing"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+)
+
+// testKey - fresh account key
+func testKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+// testTransaction - witnessed transaction of specified nonce & amount, signed by sending account's key
+func testTransaction(t *testing.T, key *ecdsa.PrivateKey, nonce uint64, to common.Address, amount Amount) *Transaction {
+	tx := NewTransaction(nonce, *NewAccount(PubkeyToAddress(&key.PublicKey)), to, amount, nil, nil, nil)
+
+	if err := tx.SignWith(key); err != nil {
+		t.Fatal(err)
+	}
+
+	witness := NewWitness(1000, HexToSignature("01"), 100)
+	tx.InitialWitness = &witness
+
+	return tx
+}
+
+// testChain - chain holding count transactions sent by account of key
+func testChain(t *testing.T, key *ecdsa.PrivateKey, count int) *Chain {
+	ch := &Chain{}
+
+	for x := 0; x < count; x++ {
+		ch.AddTransaction(testTransaction(t, key, uint64(x), common.HexToAddress("02"), NewAmount(1)))
+	}
+
+	return ch
+}
+
+func TestValidateBatch(t *testing.T) {
+	key := testKey(t)
+	host := testChain(t, key, 5)
+
+	local := &Chain{}
+	local.AddTransaction(host.Transactions[0])
+	local.AddTransaction(host.Transactions[1])
+
+	unwitnessed := *host.Transactions[2]
+	unwitnessed.InitialWitness = nil
+
+	forged := *host.Transactions[2]
+	forged.SendingAccount = *NewAccount(common.HexToAddress("03"))
+	forged.rehash()
+
+	tests := []struct {
+		name  string
+		batch []*Transaction
+		valid bool
+	}{
+		{"extends chain", host.TransactionsSince(2, 2), true},
+		{"empty", nil, true},
+		{"missing versions", host.TransactionsSince(3, 2), false},
+		{"nil transaction", []*Transaction{nil}, false},
+		{"not witnessed", []*Transaction{&unwitnessed}, false},
+		{"forged sender", []*Transaction{&forged}, false},
+	}
+
+	for _, test := range tests {
+		if err := local.ValidateBatch(test.batch); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %v, got %v", test.name, test.valid, err)
+		}
+	}
+}
+
+func TestValidateBatchCheckpoints(t *testing.T) {
+	key := testKey(t)
+	host := testCheckpointedChain(t, key, 5, 5)
+	other := testCheckpointedChain(t, testKey(t), 5, 5)
+
+	local := &Chain{Checkpoints: host.Checkpoints} // Checkpoint known before its transactions
+	local.AddTransaction(host.Transactions[0])
+	local.AddTransaction(host.Transactions[1])
+
+	tests := []struct {
+		name  string
+		batch []*Transaction
+		err   error
+	}{
+		{"matches checkpoint", host.TransactionsSince(2, 3), nil},
+		{"checkpoint not yet covered", host.TransactionsSince(2, 2), nil},
+		{"contradicts checkpoint", other.TransactionsSince(2, 3), ErrCheckpointRoot},
+	}
+
+	for _, test := range tests {
+		if err := local.ValidateBatch(test.batch); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestReadLegacyChain(t *testing.T) {
+	dir, err := os.MkdirTemp("", "legacy")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := dir + string(os.PathSeparator)
+	five, negative := 5, -5
+
+	tests := []struct {
+		name   string
+		amount *int
+		want   Amount
+		valid  bool
+	}{
+		{"whole coins", &five, NewAmount(5), true},
+		{"no amount", nil, Amount{}, true},
+		{"negative amount", &negative, Amount{}, false},
+	}
+
+	for _, test := range tests {
+		legacy := &legacyChain{Version: 1, Transactions: []*legacyTransaction{{Data: legacyTransactionData{Nonce: 1, Amount: test.amount}, ChainVersion: 1}}}
+
+		if err := common.WriteGob(ChainPath(path, nil), legacy); err != nil {
+			t.Fatal(err)
+		}
+
+		ch := ReadChainFromMemory(path)
+
+		if (ch != nil) != test.valid {
+			t.Errorf("%s: expected valid %v", test.name, test.valid)
+		} else if ch != nil && (len(ch.Transactions) != 1 || ch.Transactions[0].Data.Amount.Cmp(test.want) != 0) {
+			t.Errorf("%s: legacy amount not migrated", test.name)
+		}
+	}
+}
+
+func TestReadBaselineChain(t *testing.T) {
+	dir, err := os.MkdirTemp("", "baseline")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := dir + string(os.PathSeparator)
+	id := common.Identifier{0xab}
+
+	// Chain written by node preceding Amount & common hash, address & identifier types
+	raw, err := os.ReadFile("testdata/legacyChain.gob")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(ChainPath(path, id), raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ch := ReadChainWithIdentifier(path, id)
+
+	if ch == nil {
+		t.Fatalf("baseline chain not migrated")
+	}
+
+	if !ch.Identifier.Equal(id) || ch.Version != 2 || len(ch.Transactions) != 2 || ch.NodeDb == nil || ch.NodeDb.Len() != 1 {
+		t.Fatalf("unexpected migrated chain %+v", ch)
+	}
+
+	transfer, creation := ch.Transactions[0], ch.Transactions[1]
+
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"amount", transfer.Data.Amount.Cmp(NewAmount(1000)) == 0},
+		{"recipient", transfer.Data.Recipient != nil && *transfer.Data.Recipient == common.HexToAddress("0203")},
+		{"sender", transfer.SendingAccount.Address == common.HexToAddress("281055afc982d96fab65b3a49cac8b878184cb16")},
+		{"hash", transfer.Hash() != common.Hash{}},
+		{"parent hash", transfer.Data.ParentHash != nil && *transfer.Data.ParentHash == common.Hash{0x09}},
+		{"witness", transfer.InitialWitness != nil && transfer.InitialWitness.WitnessedTxCount == 1000},
+		{"contract creation", creation.Data.Recipient == nil && creation.Data.Amount.IsZero() && string(creation.Data.Payload) == "contract"},
+		{"parent contract", ch.ParentContract != nil && len(ch.ParentContract.Identifier) == 1},
+	}
+
+	for _, test := range tests {
+		if !test.ok {
+			t.Errorf("%s not migrated", test.name)
+		}
+	}
+}
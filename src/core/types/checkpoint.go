@@ -0,0 +1,360 @@
+package types
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/core/merkle"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+)
+
+// CheckpointPolicy - rules for creating & accepting chain checkpoints
+type CheckpointPolicy struct {
+	Interval      int // Number of transactions after which new checkpoint is due
+	MinSignatures int // Minimum number of signatures by distinct, reputable witnesses
+	MinReputation int // Minimum reputation of witness for its signature to count
+
+	Witnesses []discovery.NodeID // Witness nodes trusted to sign checkpoints & snapshots; configured by operator
+}
+
+// Checkpointing - policy used to create & verify checkpoints
+var Checkpointing = CheckpointPolicy{Interval: 100, MinSignatures: 1, MinReputation: 10}
+
+// IsWitness - check if node with specified ID is in configured witness set
+func (policy CheckpointPolicy) IsWitness(id discovery.NodeID) bool {
+	for _, witness := range policy.Witnesses {
+		if witness == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseWitnesses - parse comma-separated list of hex witness node IDs
+func ParseWitnesses(list string) ([]discovery.NodeID, error) {
+	var witnesses []discovery.NodeID
+
+	for _, field := range strings.Split(list, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+
+		var id discovery.NodeID
+
+		if err := id.UnmarshalText([]byte(field)); err != nil {
+			return nil, errors.New("invalid witness " + field + ": " + err.Error())
+		}
+
+		witnesses = append(witnesses, id)
+	}
+
+	return witnesses, nil
+}
+
+var (
+	// ErrNoCheckpointTransactions - returned when creating checkpoint without transactions since last checkpoint
+	ErrNoCheckpointTransactions = errors.New("no transactions since last checkpoint")
+
+	// ErrCheckpointParent - returned when checkpoint does not directly follow latest checkpoint
+	ErrCheckpointParent = errors.New("checkpoint does not extend latest checkpoint")
+
+	// ErrCheckpointRoot - returned when checkpoint transaction root does not match transactions it covers
+	ErrCheckpointRoot = errors.New("checkpoint transaction root mismatch")
+
+	// ErrInsufficientSignatures - returned when checkpoint lacks signatures of enough reputable witnesses
+	ErrInsufficientSignatures = errors.New("checkpoint not signed by enough reputable witnesses")
+)
+
+// ReputationFunc - reputation of witness node with specified ID, as seen by verifying node
+type ReputationFunc func(discovery.NodeID) int
+
+// Header - checkpoint committing to transactions added to chain since previous checkpoint
+type Header struct {
+	Number      int         `json:"number"`     // Checkpoint height; first checkpoint has number 1
+	ParentHash  common.Hash `json:"parentHash"` // Hash of previous checkpoint (zero for first checkpoint)
+	TxRoot      common.Hash `json:"transactionsRoot"`
+	FromVersion int         `json:"from"` // First chain version covered
+	ToVersion   int         `json:"to"`   // Last chain version covered
+	Time        time.Time   `json:"timestamp"`
+
+	Signatures []CheckpointSignature `json:"signatures"`
+}
+
+// CheckpointSignature - signature of checkpoint hash by witness node's identity key
+type CheckpointSignature struct {
+	Witness   discovery.NodeID `json:"witness"`
+	Signature []byte           `json:"signature"`
+}
+
+// Hash - hash of checkpoint fields; signatures are not covered
+func (h *Header) Hash() common.Hash {
+	hash := sha256.New()
+
+	var b [8]byte
+
+	for _, n := range []int{h.Number, h.FromVersion, h.ToVersion} {
+		binary.BigEndian.PutUint64(b[:], uint64(n))
+		hash.Write(b[:])
+	}
+
+	hash.Write(h.ParentHash[:])
+	hash.Write(h.TxRoot[:])
+	writeField(hash, []byte(h.Time.UTC().Format(time.RFC3339Nano)))
+
+	return common.BytesToHash(hash.Sum(nil))
+}
+
+// Sign - sign checkpoint with witness node's identity key, replacing earlier signature of same witness
+func (h *Header) Sign(key *ecdsa.PrivateKey) error {
+	sigs, err := signHash(h.Signatures, h.Hash(), key)
+
+	if err != nil {
+		return err
+	}
+
+	h.Signatures = sigs
+
+	return nil
+}
+
+// VerifySignatures - check that checkpoint carries valid signatures of at least policy.MinSignatures
+// distinct witnesses with reputation of at least policy.MinReputation
+func (h *Header) VerifySignatures(reputation ReputationFunc, policy CheckpointPolicy) error {
+	return verifyHashSignatures(h.Signatures, h.Hash(), reputation, policy)
+}
+
+// signHash - add signature of hash by witness key to signatures, replacing earlier signature of same witness
+func signHash(sigs []CheckpointSignature, hash common.Hash, key *ecdsa.PrivateKey) ([]CheckpointSignature, error) {
+	sig, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+
+	if err != nil {
+		return nil, err
+	}
+
+	witness := discovery.PubkeyToNodeID(&key.PublicKey)
+
+	for x := range sigs {
+		if sigs[x].Witness == witness {
+			sigs[x].Signature = sig
+			return sigs, nil
+		}
+	}
+
+	return append(sigs, CheckpointSignature{Witness: witness, Signature: sig}), nil
+}
+
+// verifyHashSignatures - check that signatures of hash include valid signatures of enough distinct, reputable witnesses
+func verifyHashSignatures(sigs []CheckpointSignature, hash common.Hash, reputation ReputationFunc, policy CheckpointPolicy) error {
+	signers := make(map[discovery.NodeID]bool)
+
+	for _, sig := range sigs {
+		if signers[sig.Witness] || reputation(sig.Witness) < policy.MinReputation {
+			continue
+		}
+
+		pub, err := sig.Witness.Pubkey()
+
+		if err != nil || !ecdsa.VerifyASN1(pub, hash[:], sig.Signature) {
+			continue
+		}
+
+		signers[sig.Witness] = true
+	}
+
+	if len(signers) < policy.MinSignatures {
+		return ErrInsufficientSignatures
+	}
+
+	return nil
+}
+
+// verifyParent - check that checkpoint directly follows parent (nil for first checkpoint)
+func (h *Header) verifyParent(parent *Header) error {
+	if parent == nil {
+		if h.Number != 1 || h.ParentHash != (common.Hash{}) || h.FromVersion != 1 {
+			return ErrCheckpointParent
+		}
+	} else if h.Number != parent.Number+1 || h.ParentHash != parent.Hash() || h.FromVersion != parent.ToVersion+1 {
+		return ErrCheckpointParent
+	}
+
+	if h.ToVersion < h.FromVersion {
+		return ErrCheckpointParent
+	}
+
+	return nil
+}
+
+// VerifyHeaders - check that headers form unbroken sequence of checkpoints following parent (nil if
+// headers start at first checkpoint), each signed by enough reputable witnesses
+func VerifyHeaders(parent *Header, headers []*Header, reputation ReputationFunc, policy CheckpointPolicy) error {
+	for _, h := range headers {
+		if h == nil {
+			return errors.New("invalid headers: nil checkpoint")
+		}
+
+		err := h.verifyParent(parent)
+
+		if err != nil {
+			return fmt.Errorf("invalid checkpoint %d: %s", h.Number, err.Error())
+		}
+
+		err = h.VerifySignatures(reputation, policy)
+
+		if err == ErrInsufficientSignatures {
+			return err // Returned as is; callers tell missing reputable witnesses apart from invalid headers
+		} else if err != nil {
+			return fmt.Errorf("invalid checkpoint %d: %s", h.Number, err.Error())
+		}
+
+		parent = h
+	}
+
+	return nil
+}
+
+// LatestCheckpoint - most recent checkpoint of chain (nil if none)
+func (RefChain Chain) LatestCheckpoint() *Header {
+	if len(RefChain.Checkpoints) == 0 {
+		return nil
+	}
+
+	return RefChain.Checkpoints[len(RefChain.Checkpoints)-1]
+}
+
+// checkpointedVersion - last chain version covered by checkpoint (0 if none)
+func (RefChain Chain) checkpointedVersion() int {
+	if latest := RefChain.LatestCheckpoint(); latest != nil {
+		return latest.ToVersion
+	}
+
+	return 0
+}
+
+// CheckpointDue - check if enough transactions were added since latest checkpoint for new checkpoint
+func (RefChain Chain) CheckpointDue() bool {
+	return Checkpointing.Interval > 0 && RefChain.Version-RefChain.checkpointedVersion() >= Checkpointing.Interval
+}
+
+// TransactionsRoot - Merkle root of signing hashes of transactions with chain versions in specified range
+func (RefChain Chain) TransactionsRoot(FromVersion int, ToVersion int) common.Hash {
+	return merkle.Root(RefChain.transactionHashes(FromVersion, ToVersion))
+}
+
+// transactionHashes - signing hashes of transactions with chain versions in specified range, in version
+// order; computed from transaction contents rather than hash carried by transaction
+func (RefChain Chain) transactionHashes(FromVersion int, ToVersion int) []common.Hash {
+	var hashes []common.Hash
+
+	for _, tx := range RefChain.TransactionsSince(FromVersion-1, ToVersion-FromVersion+1) {
+		if tx.ChainVersion <= ToVersion {
+			hashes = append(hashes, tx.SigningHash())
+		}
+	}
+
+	return hashes
+}
+
+// verifyKnownCheckpoints - check that transactions extending chain match transaction roots of chain's
+// checkpoints covering them; checkpoints extending past last transaction are checked once remaining
+// transactions arrive
+func (RefChain Chain) verifyKnownCheckpoints(Transactions []*Transaction) error {
+	if len(Transactions) == 0 {
+		return nil
+	}
+
+	last := Transactions[len(Transactions)-1].ChainVersion
+
+	for _, h := range RefChain.Checkpoints {
+		if h.ToVersion <= RefChain.Version || h.ToVersion > last {
+			continue
+		}
+
+		hashes := RefChain.transactionHashes(h.FromVersion, RefChain.Version)
+
+		for _, tx := range Transactions {
+			if tx.ChainVersion >= h.FromVersion && tx.ChainVersion <= h.ToVersion {
+				hashes = append(hashes, tx.SigningHash())
+			}
+		}
+
+		if len(hashes) != h.ToVersion-h.FromVersion+1 || merkle.Root(hashes) != h.TxRoot {
+			return ErrCheckpointRoot
+		}
+	}
+
+	return nil
+}
+
+// NewCheckpoint - create unsigned checkpoint covering transactions added since latest checkpoint
+func (RefChain Chain) NewCheckpoint() (*Header, error) {
+	from := RefChain.checkpointedVersion() + 1
+
+	if RefChain.Version < from {
+		return nil, ErrNoCheckpointTransactions
+	}
+
+	h := &Header{Number: len(RefChain.Checkpoints) + 1, TxRoot: RefChain.TransactionsRoot(from, RefChain.Version), FromVersion: from, ToVersion: RefChain.Version, Time: time.Now().UTC()}
+
+	if latest := RefChain.LatestCheckpoint(); latest != nil {
+		h.ParentHash = latest.Hash()
+	}
+
+	return h, nil
+}
+
+// AddCheckpoint - verify checkpoint against chain & add it; checkpoint must extend latest checkpoint,
+// commit to transactions held by chain & be signed by enough reputable witnesses
+func (RefChain *Chain) AddCheckpoint(h *Header, reputation ReputationFunc) error {
+	err := h.verifyParent(RefChain.LatestCheckpoint())
+
+	if err != nil {
+		return err
+	}
+
+	if h.ToVersion > RefChain.Version || RefChain.TransactionsRoot(h.FromVersion, h.ToVersion) != h.TxRoot {
+		return ErrCheckpointRoot
+	}
+
+	err = h.VerifySignatures(reputation, Checkpointing)
+
+	if err != nil {
+		return err
+	}
+
+	RefChain.Checkpoints = append(RefChain.Checkpoints, h)
+
+	return nil
+}
+
+// CheckpointsSince - checkpoints covering chain versions after specified version, up to specified version
+func (RefChain Chain) CheckpointsSince(Version int, UpTo int) []*Header {
+	var headers []*Header
+
+	for _, h := range RefChain.Checkpoints {
+		if h.ToVersion > Version && h.ToVersion <= UpTo {
+			headers = append(headers, h)
+		}
+	}
+
+	return headers
+}
+
+// WitnessReputation - reputation of witness as seen by current node; only configured witnesses & current
+// node itself are trusted, as peer reputation grows with mere contact & says nothing about witnessing
+func (RefChain Chain) WitnessReputation(id discovery.NodeID) int {
+	if Checkpointing.IsWitness(id) || (RefChain.NodeDb != nil && id == RefChain.NodeDb.SelfRef) {
+		return math.MaxInt32
+	}
+
+	return 0
+}
@@ -0,0 +1,195 @@
+package types
+
+import (
+	"crypto/ecdsa"
+	"testing"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+)
+
+// testCheckpoint - checkpoint covering transactions since latest checkpoint, signed with key & added to chain
+func testCheckpoint(t *testing.T, ch *Chain, key *ecdsa.PrivateKey) *Header {
+	h, err := ch.NewCheckpoint()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ch.AddCheckpoint(h, ch.WitnessReputation); err != nil {
+		t.Fatal(err)
+	}
+
+	return h
+}
+
+// testCheckpointedChain - chain of count transactions sent by account of key, checkpointed every
+// interval transactions by witness node of key
+func testCheckpointedChain(t *testing.T, key *ecdsa.PrivateKey, count int, interval int) *Chain {
+	ch := &Chain{NodeDb: &discovery.NodeDatabase{SelfRef: discovery.PubkeyToNodeID(&key.PublicKey)}}
+
+	for x := 0; x < count; x++ {
+		ch.AddTransaction(testTransaction(t, key, uint64(x), common.HexToAddress("02"), NewAmount(1)))
+
+		if (x+1)%interval == 0 {
+			testCheckpoint(t, ch, key)
+		}
+	}
+
+	return ch
+}
+
+func TestNewCheckpoint(t *testing.T) {
+	key := testKey(t)
+	ch := testCheckpointedChain(t, key, 10, 5)
+
+	first, second := ch.Checkpoints[0], ch.Checkpoints[1]
+
+	if first.Number != 1 || first.FromVersion != 1 || first.ToVersion != 5 || len(first.Signatures) != 1 {
+		t.Errorf("unexpected first checkpoint %+v", first)
+	}
+
+	if second.ParentHash != first.Hash() || second.FromVersion != first.ToVersion+1 || second.ToVersion != ch.Version {
+		t.Errorf("checkpoint does not extend previous checkpoint")
+	}
+
+	if _, err := ch.NewCheckpoint(); err != ErrNoCheckpointTransactions {
+		t.Errorf("checkpoint created without transactions (%v)", err)
+	}
+}
+
+func TestVerifyHeaders(t *testing.T) {
+	key := testKey(t)
+	ch := testCheckpointedChain(t, key, 10, 5)
+
+	tests := []struct {
+		name       string
+		headers    []*Header
+		reputation ReputationFunc
+		valid      bool
+	}{
+		{"valid", ch.Checkpoints, ch.WitnessReputation, true},
+		{"unreputable witness", ch.Checkpoints, func(discovery.NodeID) int { return 0 }, false},
+		{"not starting at first checkpoint", ch.Checkpoints[1:], ch.WitnessReputation, false},
+	}
+
+	for _, test := range tests {
+		if err := VerifyHeaders(nil, test.headers, test.reputation, Checkpointing); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %v, got %v", test.name, test.valid, err)
+		}
+	}
+
+	// Missing reputable witnesses must stay distinguishable from invalid headers
+	if err := VerifyHeaders(nil, ch.Checkpoints, func(discovery.NodeID) int { return 0 }, Checkpointing); err != ErrInsufficientSignatures {
+		t.Errorf("expected %v, got %v", ErrInsufficientSignatures, err)
+	}
+}
+
+func TestAddCheckpoint(t *testing.T) {
+	key := testKey(t)
+	ch := testCheckpointedChain(t, key, 10, 5)
+
+	first, second := ch.Checkpoints[0], ch.Checkpoints[1]
+
+	// Replica syncing chain verifies checkpoints against its own copy of transactions
+	replica := &Chain{NodeDb: ch.NodeDb}
+
+	for _, tx := range ch.Transactions[:first.ToVersion] {
+		replica.AddTransaction(tx)
+	}
+
+	unsigned := *first
+	unsigned.Signatures = nil
+
+	tests := []struct {
+		name string
+		h    *Header
+		err  error
+	}{
+		{"missing parent", second, ErrCheckpointParent},
+		{"unsigned", &unsigned, ErrInsufficientSignatures},
+		{"valid", first, nil},
+		{"over missing transactions", second, ErrCheckpointRoot},
+	}
+
+	for _, test := range tests {
+		if err := replica.AddCheckpoint(test.h, ch.WitnessReputation); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestWitnessReputation(t *testing.T) {
+	defer func(witnesses []discovery.NodeID) { Checkpointing.Witnesses = witnesses }(Checkpointing.Witnesses)
+
+	key := testKey(t)
+	ch := testCheckpointedChain(t, key, 5, 5)
+	witness := discovery.PubkeyToNodeID(&key.PublicKey)
+
+	// Replica has only contacted witness, often enough to exceed minimum reputation
+	db, err := discovery.NewNodeDatabase(discovery.RandomNodeID(), "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.AddPeer(&discovery.Peer{ID: witness, Addresses: []string{"1.1.1.1"}})
+
+	for x := 0; x <= Checkpointing.MinReputation; x++ {
+		db.RecordContact(witness, time.Millisecond)
+	}
+
+	tests := []struct {
+		name      string
+		witnesses []discovery.NodeID
+		err       error
+	}{
+		{"contact history only", nil, ErrInsufficientSignatures},
+		{"other witness configured", []discovery.NodeID{discovery.RandomNodeID()}, ErrInsufficientSignatures},
+		{"configured witness", []discovery.NodeID{witness}, nil},
+	}
+
+	for _, test := range tests {
+		Checkpointing.Witnesses = test.witnesses
+
+		replica := &Chain{NodeDb: db}
+
+		for _, tx := range ch.Transactions {
+			replica.AddTransaction(tx)
+		}
+
+		if err := replica.AddCheckpoint(ch.Checkpoints[0], replica.WitnessReputation); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestParseWitnesses(t *testing.T) {
+	id := discovery.RandomNodeID()
+
+	tests := []struct {
+		name  string
+		list  string
+		count int
+		valid bool
+	}{
+		{"empty", "", 0, true},
+		{"single", id.String(), 1, true},
+		{"list with spaces", id.String() + ", " + id.String(), 2, true},
+		{"invalid hex", "zz", 0, false},
+		{"short id", "0102", 0, false},
+	}
+
+	for _, test := range tests {
+		witnesses, err := ParseWitnesses(test.list)
+
+		if (err == nil) != test.valid || len(witnesses) != test.count {
+			t.Errorf("%s: expected %d witnesses (valid %v), got %d (%v)", test.name, test.count, test.valid, len(witnesses), err)
+		}
+	}
+}
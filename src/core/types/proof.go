@@ -0,0 +1,118 @@
+package types
+
+import (
+	"errors"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/core/merkle"
+)
+
+var (
+	// ErrUnknownTransaction - returned when proving transaction not held by chain
+	ErrUnknownTransaction = errors.New("transaction not in chain")
+
+	// ErrNotCheckpointed - returned when proving transaction not yet covered by checkpoint
+	ErrNotCheckpointed = errors.New("transaction not yet covered by checkpoint")
+
+	// ErrProofCheckpoint - returned when verifying proof against checkpoint it was not made for
+	ErrProofCheckpoint = errors.New("proof refers to other checkpoint")
+)
+
+// TxProof - proof that transaction is covered by checkpoint's transaction root
+type TxProof struct {
+	TxHash     common.Hash  `json:"txhash"`     // Signing hash of transaction, as committed to by checkpoint
+	Checkpoint int          `json:"checkpoint"` // Number of checkpoint proof is made against
+	Proof      merkle.Proof `json:"proof"`
+}
+
+// ProveTransaction - inclusion proof of transaction with specified hash against checkpoint covering it
+func (RefChain Chain) ProveTransaction(hash common.Hash) (*TxProof, error) {
+	var target *Transaction
+
+	for _, tx := range RefChain.Transactions {
+		if tx != nil && tx.Hash() == hash {
+			target = tx
+			break
+		}
+	}
+
+	if target == nil {
+		return nil, ErrUnknownTransaction
+	}
+
+	version, leaf := target.ChainVersion, target.SigningHash()
+
+	for _, h := range RefChain.Checkpoints {
+		if version < h.FromVersion || version > h.ToVersion {
+			continue
+		}
+
+		hashes := RefChain.transactionHashes(h.FromVersion, h.ToVersion)
+
+		for x := range hashes {
+			if hashes[x] != leaf {
+				continue
+			}
+
+			proof, err := merkle.Prove(hashes, x)
+
+			if err != nil {
+				return nil, err
+			}
+
+			return &TxProof{TxHash: leaf, Checkpoint: h.Number, Proof: *proof}, nil
+		}
+	}
+
+	return nil, ErrNotCheckpointed
+}
+
+// Verify - check that proof links transaction to transaction root of specified checkpoint; leaf is
+// recomputed from transaction contents, so transactions carrying forged hash are rejected
+func (p *TxProof) Verify(tx *Transaction, h *Header) error {
+	if h == nil || p.Checkpoint != h.Number {
+		return ErrProofCheckpoint
+	}
+
+	if tx == nil {
+		return merkle.ErrInvalidProof
+	}
+
+	return p.Proof.Verify(h.TxRoot, tx.SigningHash())
+}
+
+// ProveAccount - checkpointed transactions sent or received by account, each with inclusion proof;
+// transactions not yet covered by checkpoint are omitted. Fails with ErrPruned if part of account's
+// history was pruned.
+func (RefChain Chain) ProveAccount(addr common.Address) ([]*Transaction, []*TxProof, error) {
+	if RefChain.PrunedVersion() > 0 && RefChain.Snapshot.Account(addr) != nil {
+		return nil, nil, ErrPruned
+	}
+
+	var txs []*Transaction
+	var proofs []*TxProof
+
+	checkpointed := RefChain.checkpointedVersion()
+
+	for _, tx := range RefChain.Transactions {
+		if tx == nil || tx.ChainVersion > checkpointed || !tx.Involves(addr) {
+			continue
+		}
+
+		proof, err := RefChain.ProveTransaction(tx.Hash())
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		txs = append(txs, tx)
+		proofs = append(proofs, proof)
+	}
+
+	return txs, proofs, nil
+}
+
+// Involves - check if transaction is sent or received by account
+func (tx *Transaction) Involves(addr common.Address) bool {
+	return tx.SendingAccount.Address == addr || (tx.Data.Recipient != nil && *tx.Data.Recipient == addr)
+}
@@ -0,0 +1,69 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/mitsukomegumi/indo-go/src/core/merkle"
+)
+
+func TestProveTransaction(t *testing.T) {
+	key := testKey(t)
+	ch := testCheckpointedChain(t, key, 12, 5) // Last 2 transactions not checkpointed
+
+	tests := []struct {
+		name string
+		tx   *Transaction
+		err  error
+	}{
+		{"first checkpoint", ch.Transactions[0], nil},
+		{"second checkpoint", ch.Transactions[7], nil},
+		{"not checkpointed", ch.Transactions[11], ErrNotCheckpointed},
+		{"unknown", &Transaction{}, ErrUnknownTransaction},
+	}
+
+	for _, test := range tests {
+		proof, err := ch.ProveTransaction(test.tx.Hash())
+
+		if err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		} else if err == nil && proof.Verify(test.tx, ch.checkpoint(proof.Checkpoint)) != nil {
+			t.Errorf("%s: transaction proof rejected", test.name)
+		}
+	}
+}
+
+func TestVerifyTxProof(t *testing.T) {
+	key := testKey(t)
+	ch := testCheckpointedChain(t, key, 10, 5)
+	tx := ch.Transactions[2]
+
+	proof, err := ch.ProveTransaction(tx.Hash())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Transaction carrying hash of proven transaction, but crediting larger amount
+	forged := *tx
+	forged.Data.Amount = NewAmount(1000)
+
+	tests := []struct {
+		name string
+		tx   *Transaction
+		h    *Header
+		err  error
+	}{
+		{"valid", tx, ch.Checkpoints[0], nil},
+		{"other transaction", ch.Transactions[3], ch.Checkpoints[0], merkle.ErrInvalidProof},
+		{"forged leaf hash", &forged, ch.Checkpoints[0], merkle.ErrInvalidProof},
+		{"no transaction", nil, ch.Checkpoints[0], merkle.ErrInvalidProof},
+		{"other checkpoint", tx, ch.Checkpoints[1], ErrProofCheckpoint},
+		{"no checkpoint", tx, nil, ErrProofCheckpoint},
+	}
+
+	for _, test := range tests {
+		if err := proof.Verify(test.tx, test.h); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package types
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+)
+
+// RegistryFile - name of file listing identifiers of chains current node participates in
+const RegistryFile = "Chains.gob"
+
+// ErrUnknownChain - returned when chain with requested identifier is not held by current node
+var ErrUnknownChain = errors.New("chain not hosted by current node")
+
+// ChainRegistry - chains current node participates in, keyed by identifier
+type ChainRegistry struct {
+	mu     sync.RWMutex
+	chains map[string]*Chain
+}
+
+// NewChainRegistry - initialize empty chain registry
+func NewChainRegistry() *ChainRegistry {
+	return &ChainRegistry{chains: make(map[string]*Chain)}
+}
+
+// Register - add chain to registry, replacing any registered chain with same identifier
+func (registry *ChainRegistry) Register(Ch *Chain) error {
+	if Ch == nil {
+		return errors.New("invalid chain")
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.chains[Ch.Identifier.String()] = Ch
+
+	return nil
+}
+
+// Unregister - remove chain with specified identifier from registry, returning false if not registered
+func (registry *ChainRegistry) Unregister(id common.Identifier) bool {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if _, found := registry.chains[id.String()]; !found {
+		return false
+	}
+
+	delete(registry.chains, id.String())
+
+	return true
+}
+
+// Get - chain with specified identifier
+func (registry *ChainRegistry) Get(id common.Identifier) (*Chain, error) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	Ch, found := registry.chains[id.String()]
+
+	if !found {
+		return nil, ErrUnknownChain
+	}
+
+	return Ch, nil
+}
+
+// Len - number of registered chains
+func (registry *ChainRegistry) Len() int {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	return len(registry.chains)
+}
+
+// Identifiers - identifiers of registered chains, in hex order (default chain first)
+func (registry *ChainRegistry) Identifiers() []common.Identifier {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	keys := make([]string, 0, len(registry.chains))
+
+	for key := range registry.chains {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	ids := make([]common.Identifier, len(keys))
+
+	for x, key := range keys {
+		ids[x] = registry.chains[key].Identifier
+	}
+
+	return ids
+}
+
+// Chains - registered chains, in identifier order
+func (registry *ChainRegistry) Chains() []*Chain {
+	var chains []*Chain
+
+	for _, id := range registry.Identifiers() {
+		if Ch, err := registry.Get(id); err == nil {
+			chains = append(chains, Ch)
+		}
+	}
+
+	return chains
+}
+
+// WriteRegistryToMemory - write every registered chain, & list of registered identifiers, to specified path
+func (registry *ChainRegistry) WriteRegistryToMemory(path string) error {
+	ids := registry.Identifiers()
+
+	for _, Ch := range registry.Chains() {
+		err := Ch.WriteChainToMemory(path)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return common.WriteGob(path+RegistryFile, ids)
+}
+
+// ReadRegistryFromMemory - read chains listed in registry at specified path; the default chain
+// is registered whenever present, so nodes predating the registry keep hosting it
+func ReadRegistryFromMemory(path string) (*ChainRegistry, error) {
+	registry := NewChainRegistry()
+
+	var ids []common.Identifier
+
+	common.ReadGob(path+RegistryFile, &ids) // Not present until registry is first written
+
+	if Ch := ReadChainFromMemory(path); Ch != nil && len(Ch.Identifier) == 0 {
+		registry.Register(Ch)
+	}
+
+	for _, id := range ids {
+		if len(id) == 0 {
+			continue // Default chain read above
+		}
+
+		Ch := ReadChainWithIdentifier(path, id)
+
+		if Ch == nil {
+			common.ThrowWarning("chain " + id.String() + " listed in registry but not found")
+			continue
+		}
+
+		registry.Register(Ch)
+	}
+
+	return registry, nil
+}
@@ -0,0 +1,72 @@
+package types
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+)
+
+func TestChainRegistry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chains")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := dir + string(os.PathSeparator)
+
+	db := &discovery.NodeDatabase{SelfRef: discovery.RandomNodeID()}
+
+	registry := NewChainRegistry()
+	registry.Register(&Chain{NodeDb: db, Version: 1})
+	registry.Register(&Chain{Identifier: common.Identifier{0xab, 0x01}, NodeDb: db, Version: 2})
+	registry.Register(&Chain{Identifier: common.Identifier{0x0f}, NodeDb: db, Version: 3})
+
+	err = registry.WriteRegistryToMemory(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(path + "ab01Chain.gob"); err != nil {
+		t.Errorf("chain not written to identifier path: %s", err.Error())
+	}
+
+	read, err := ReadRegistryFromMemory(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ids := read.Identifiers(); len(ids) != 3 || len(ids[0]) != 0 || ids[1].String() != "0f" || ids[2].String() != "ab01" {
+		t.Fatalf("unexpected registered chains %v", ids)
+	}
+
+	tests := []struct {
+		id      common.Identifier
+		version int
+		err     error
+	}{
+		{nil, 1, nil},
+		{common.Identifier{0x0f}, 3, nil},
+		{common.Identifier{0xab, 0x01}, 2, nil},
+		{common.Identifier{0x01}, 0, ErrUnknownChain},
+	}
+
+	for _, test := range tests {
+		ch, err := read.Get(test.id)
+
+		if err != test.err || (err == nil && ch.Version != test.version) {
+			t.Errorf("chain %q: expected version %d (%v), got %v", test.id.String(), test.version, test.err, err)
+		}
+	}
+
+	if ch := ReadChainFromMemory(path); ch == nil || ch.Version != 1 {
+		t.Errorf("default chain not read from default path")
+	}
+}
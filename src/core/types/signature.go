@@ -1,6 +1,10 @@
 package types
 
-import "math/big"
+import (
+	"math/big"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+)
 
 //Signature - data representing digital verification, as well as any payload attatched to the verification.
 type Signature []byte
@@ -19,4 +23,4 @@ func BigToSignature(b *big.Int) Signature { return BytesToSignature(b.Bytes()) }
 func IntToSignature(b *int) Signature { return BytesToSignature((*big.NewInt(int64(*b))).Bytes()) }
 
 // HexToSignature - Convert hex string to Signature
-func HexToSignature(s string) Signature { return BytesToSignature(FromHex(s)) }
+func HexToSignature(s string) Signature { return BytesToSignature(common.FromHex(s)) }
@@ -0,0 +1,336 @@
+package types
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/contracts"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+)
+
+// SnapshotPolicy - rules for taking state snapshots & pruning transactions they cover
+type SnapshotPolicy struct {
+	Interval int  // Number of checkpoints between snapshots (0 disables snapshots)
+	Prune    bool // Drop transactions covered by snapshot once it is taken
+}
+
+// Snapshotting - policy used to take snapshots
+var Snapshotting = SnapshotPolicy{Interval: 10}
+
+var (
+	// ErrSnapshotCheckpoint - returned when snapshot does not refer to checkpoint it is verified against
+	ErrSnapshotCheckpoint = errors.New("snapshot does not match checkpoint")
+
+	// ErrNoSnapshot - returned when requesting snapshot of chain that holds none
+	ErrNoSnapshot = errors.New("chain holds no snapshot")
+
+	// ErrStaleSnapshot - returned when snapshot is not newer than chain's latest snapshot
+	ErrStaleSnapshot = errors.New("snapshot not newer than latest snapshot")
+
+	// ErrPruned - returned when operation requires transactions pruned from chain
+	ErrPruned = errors.New("transactions pruned from chain")
+)
+
+// IssuanceFunc - reports whether transaction credits recipient without debiting sender, such as token minting
+type IssuanceFunc func(*Transaction) bool
+
+// AccountState - totals of account as of snapshot
+type AccountState struct {
+	Address  common.Address `json:"address"`
+	Received Amount         `json:"received"`
+	Sent     Amount         `json:"sent"`
+	Nonce    uint64         `json:"nonce"` // Nonce of next transaction sent from account
+}
+
+// PeerReputation - reputation of witness node as recorded by snapshotting node; reputation differs
+// between nodes, so it is neither signed nor ever adopted by verifying nodes
+type PeerReputation struct {
+	Witness    discovery.NodeID `json:"witness"`
+	Reputation int              `json:"reputation"`
+}
+
+// Snapshot - chain state as of checkpoint, signed by witnesses; transactions covered by snapshot
+// may be pruned, & new nodes may start from snapshot instead of replaying chain
+type Snapshot struct {
+	Checkpoint     int         `json:"checkpoint"` // Number of checkpoint snapshot is taken at
+	CheckpointHash common.Hash `json:"checkpointHash"`
+	Version        int         `json:"version"` // Last chain version reflected in state
+
+	Accounts   []AccountState      `json:"accounts"`       // Ordered by address
+	Contract   *contracts.Contract `json:"contract"`       // Parent contract of chain
+	Reputation []PeerReputation    `json:"reputation"`     // Ordered by witness ID; informational, not covered by hash
+	Data       []byte              `json:"data,omitempty"` // Chain-type-specific state, e.g. token parameters
+
+	Signatures []CheckpointSignature `json:"signatures"`
+}
+
+// Balance - balance of account, as received minus sent amount
+func (account *AccountState) Balance() (Amount, error) {
+	return account.Received.Sub(account.Sent)
+}
+
+// Hash - hash of snapshot fields; signatures & local reputation are not covered, so witnesses with
+// different views of their peers can co-sign same snapshot
+func (s *Snapshot) Hash() common.Hash {
+	unsigned := *s
+	unsigned.Signatures = nil
+	unsigned.Reputation = nil
+
+	b, _ := json.Marshal(unsigned)
+	sum := sha256.Sum256(b)
+
+	return common.BytesToHash(sum[:])
+}
+
+// Sign - sign snapshot with witness node's identity key, replacing earlier signature of same witness
+func (s *Snapshot) Sign(key *ecdsa.PrivateKey) error {
+	sigs, err := signHash(s.Signatures, s.Hash(), key)
+
+	if err != nil {
+		return err
+	}
+
+	s.Signatures = sigs
+
+	return nil
+}
+
+// Verify - check that snapshot is taken at specified checkpoint & signed by enough reputable witnesses
+func (s *Snapshot) Verify(h *Header, reputation ReputationFunc, policy CheckpointPolicy) error {
+	if h == nil || h.Number != s.Checkpoint || h.ToVersion != s.Version || h.Hash() != s.CheckpointHash {
+		return ErrSnapshotCheckpoint
+	}
+
+	return verifyHashSignatures(s.Signatures, s.Hash(), reputation, policy)
+}
+
+// Account - state of account with specified address (nil if account has no transactions)
+func (s *Snapshot) Account(addr common.Address) *AccountState {
+	x := sort.Search(len(s.Accounts), func(i int) bool {
+		return !s.Accounts[i].Address.Less(addr)
+	})
+
+	if x < len(s.Accounts) && s.Accounts[x].Address == addr {
+		return &s.Accounts[x]
+	}
+
+	return nil
+}
+
+// SnapshotDue - check if latest checkpoint is due for snapshot
+func (RefChain Chain) SnapshotDue() bool {
+	latest := RefChain.LatestCheckpoint()
+
+	if Snapshotting.Interval <= 0 || latest == nil || latest.Number%Snapshotting.Interval != 0 {
+		return false
+	}
+
+	return RefChain.Snapshot == nil || RefChain.Snapshot.Checkpoint < latest.Number
+}
+
+// NewSnapshot - unsigned snapshot of chain state at latest checkpoint, derived from chain's latest
+// snapshot & transactions since; issues reports transactions that do not debit sender (nil if none)
+func (RefChain Chain) NewSnapshot(issues IssuanceFunc) (*Snapshot, error) {
+	h := RefChain.LatestCheckpoint()
+
+	if h == nil {
+		return nil, errors.New("no checkpoint to take snapshot at")
+	}
+
+	accounts := make(map[common.Address]*AccountState)
+	from := 0
+
+	if base := RefChain.Snapshot; base != nil {
+		if base.Checkpoint >= h.Number {
+			return nil, ErrStaleSnapshot
+		}
+
+		for x := range base.Accounts {
+			account := base.Accounts[x]
+			accounts[account.Address] = &account
+		}
+
+		from = base.Version
+	}
+
+	txs := RefChain.TransactionsSince(from, h.ToVersion-from)
+
+	if len(txs) != h.ToVersion-from || (len(txs) > 0 && txs[len(txs)-1].ChainVersion != h.ToVersion) {
+		return nil, ErrPruned
+	}
+
+	for _, tx := range txs {
+		err := applyToAccounts(accounts, tx, issues)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Snapshot{Checkpoint: h.Number, CheckpointHash: h.Hash(), Version: h.ToVersion, Contract: RefChain.ParentContract}
+
+	for _, account := range accounts {
+		s.Accounts = append(s.Accounts, *account)
+	}
+
+	sort.Slice(s.Accounts, func(i, j int) bool {
+		return s.Accounts[i].Address.Less(s.Accounts[j].Address)
+	})
+
+	if RefChain.NodeDb != nil {
+		for _, peer := range RefChain.NodeDb.ListPeers() {
+			if peer.Reputation > 0 {
+				s.Reputation = append(s.Reputation, PeerReputation{Witness: peer.ID, Reputation: peer.Reputation})
+			}
+		}
+
+		sort.Slice(s.Reputation, func(i, j int) bool {
+			return bytes.Compare(s.Reputation[i].Witness[:], s.Reputation[j].Witness[:]) < 0
+		})
+	}
+
+	return s, nil
+}
+
+// applyToAccounts - credit transaction amount to recipient & debit it from sender, advancing sender's nonce
+func applyToAccounts(accounts map[common.Address]*AccountState, tx *Transaction, issues IssuanceFunc) error {
+	account := func(addr common.Address) *AccountState {
+		if accounts[addr] == nil {
+			accounts[addr] = &AccountState{Address: addr}
+		}
+		return accounts[addr]
+	}
+
+	var err error
+
+	if tx.Data.Recipient != nil {
+		recipient := account(*tx.Data.Recipient)
+		recipient.Received, err = recipient.Received.Add(tx.Data.Amount)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	sender := account(tx.SendingAccount.Address)
+
+	if issues == nil || !issues(tx) {
+		sender.Sent, err = sender.Sent.Add(tx.Data.Amount)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if tx.Data.Nonce >= sender.Nonce {
+		sender.Nonce = tx.Data.Nonce + 1
+	}
+
+	return nil
+}
+
+// checkpoint - chain's checkpoint with specified number (nil if none)
+func (RefChain Chain) checkpoint(number int) *Header {
+	if number < 1 || number > len(RefChain.Checkpoints) {
+		return nil
+	}
+
+	return RefChain.Checkpoints[number-1]
+}
+
+// AddSnapshot - verify snapshot against chain's checkpoint it is taken at & set it as chain's latest snapshot
+func (RefChain *Chain) AddSnapshot(s *Snapshot, reputation ReputationFunc) error {
+	if RefChain.Snapshot != nil && s.Checkpoint <= RefChain.Snapshot.Checkpoint {
+		return ErrStaleSnapshot
+	}
+
+	err := s.Verify(RefChain.checkpoint(s.Checkpoint), reputation, Checkpointing)
+
+	if err != nil {
+		return err
+	}
+
+	RefChain.Snapshot = s
+
+	return nil
+}
+
+// Prune - drop transactions covered by chain's latest snapshot; returns number of transactions dropped
+func (RefChain *Chain) Prune() int {
+	if RefChain.Snapshot == nil {
+		return 0
+	}
+
+	var kept []*Transaction
+
+	for _, tx := range RefChain.Transactions {
+		if tx != nil && tx.ChainVersion > RefChain.Snapshot.Version {
+			kept = append(kept, tx)
+		}
+	}
+
+	pruned := len(RefChain.Transactions) - len(kept)
+	RefChain.Transactions = kept
+
+	return pruned
+}
+
+// PrunedVersion - last chain version whose transactions chain no longer holds (0 if chain is complete)
+func (RefChain Chain) PrunedVersion() int {
+	if RefChain.Snapshot == nil {
+		return 0
+	}
+
+	held := 0
+
+	for _, tx := range RefChain.Transactions {
+		if tx != nil && tx.ChainVersion <= RefChain.Snapshot.Version {
+			held++
+		}
+	}
+
+	if held == RefChain.Snapshot.Version {
+		return 0
+	}
+
+	return RefChain.Snapshot.Version
+}
+
+// BootstrapFromSnapshot - initialize empty chain from checkpoint headers up to snapshot & snapshot taken
+// at last of them, verifying both; transactions following snapshot are synced separately
+func (RefChain *Chain) BootstrapFromSnapshot(headers []*Header, s *Snapshot, reputation ReputationFunc) error {
+	if RefChain.Version != 0 || len(RefChain.Transactions) != 0 {
+		return errors.New("cannot bootstrap non-empty chain from snapshot")
+	}
+
+	if s == nil || s.Checkpoint < 1 || s.Checkpoint != len(headers) {
+		return ErrSnapshotCheckpoint
+	}
+
+	err := VerifyHeaders(nil, headers, reputation, Checkpointing)
+
+	if err != nil {
+		return err
+	}
+
+	err = s.Verify(headers[len(headers)-1], reputation, Checkpointing)
+
+	if err != nil {
+		return err
+	}
+
+	RefChain.Checkpoints = headers
+	RefChain.Snapshot = s
+	RefChain.Version = s.Version
+
+	if s.Contract != nil {
+		RefChain.ParentContract = s.Contract
+	}
+
+	return nil
+}
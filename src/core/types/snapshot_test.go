@@ -0,0 +1,198 @@
+package types
+
+import (
+	"crypto/ecdsa"
+	"testing"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+)
+
+// testSnapshot - snapshot of chain at latest checkpoint, signed with key & added to chain
+func testSnapshot(t *testing.T, ch *Chain, key *ecdsa.PrivateKey) *Snapshot {
+	s, err := ch.NewSnapshot(nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ch.AddSnapshot(s, ch.WitnessReputation); err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestNewSnapshot(t *testing.T) {
+	key := testKey(t)
+	sender, recipient := PubkeyToAddress(&key.PublicKey), common.HexToAddress("02")
+
+	ch := testCheckpointedChain(t, key, 12, 5)
+	s := testSnapshot(t, ch, key)
+
+	if s.Checkpoint != 2 || s.Version != 10 || s.CheckpointHash != ch.Checkpoints[1].Hash() {
+		t.Fatalf("snapshot not taken at latest checkpoint: %+v", s)
+	}
+
+	if balance, _ := s.Account(recipient).Balance(); balance.String() != "10" {
+		t.Errorf("wrong recipient balance in snapshot: %s", balance)
+	}
+
+	if account := s.Account(sender); account == nil || account.Nonce != 10 || account.Sent.String() != "10" {
+		t.Errorf("wrong sender state in snapshot: %+v", account)
+	}
+
+	if s.Account(common.HexToAddress("03")) != nil {
+		t.Errorf("state of account without transactions in snapshot")
+	}
+
+	if _, err := ch.NewSnapshot(nil); err != ErrStaleSnapshot {
+		t.Errorf("second snapshot at same checkpoint taken (%v)", err)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	key := testKey(t)
+	ch := testCheckpointedChain(t, key, 12, 5)
+
+	if ch.Prune() != 0 || ch.PrunedVersion() != 0 {
+		t.Errorf("chain without snapshot pruned")
+	}
+
+	testSnapshot(t, ch, key)
+
+	if ch.PrunedVersion() != 0 {
+		t.Errorf("unpruned chain reported as pruned")
+	}
+
+	if pruned := ch.Prune(); pruned != 10 || len(ch.Transactions) != 2 || ch.PrunedVersion() != 10 {
+		t.Errorf("transactions covered by snapshot not pruned: %d pruned, %d held", pruned, len(ch.Transactions))
+	}
+
+	if _, _, err := ch.ProveAccount(common.HexToAddress("02")); err != ErrPruned {
+		t.Errorf("account proof with pruned history served (%v)", err)
+	}
+
+	if _, err := ch.NewSnapshot(nil); err != ErrStaleSnapshot {
+		t.Errorf("snapshot over pruned transactions taken (%v)", err)
+	}
+}
+
+func TestBootstrapFromSnapshot(t *testing.T) {
+	key := testKey(t)
+	ch := testCheckpointedChain(t, key, 12, 5)
+	s := testSnapshot(t, ch, key)
+	headers := ch.Checkpoints[:s.Checkpoint]
+
+	inflated := *s
+	inflated.Accounts = append([]AccountState{}, s.Accounts...)
+	inflated.Accounts[0].Received = MaxAmount()
+
+	tests := []struct {
+		name    string
+		headers []*Header
+		s       *Snapshot
+		err     error
+	}{
+		{"inflated balance", headers, &inflated, ErrInsufficientSignatures},
+		{"missing headers", headers[1:], s, ErrSnapshotCheckpoint},
+		{"no snapshot", headers, nil, ErrSnapshotCheckpoint},
+	}
+
+	for _, test := range tests {
+		if err := (&Chain{}).BootstrapFromSnapshot(test.headers, test.s, ch.WitnessReputation); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+
+	fresh := &Chain{}
+
+	if err := fresh.BootstrapFromSnapshot(headers, s, ch.WitnessReputation); err != nil || fresh.Version != s.Version {
+		t.Fatalf("snapshot rejected (%v)", err)
+	}
+
+	if err := fresh.ValidateBatch(ch.TransactionsSince(s.Version, 2)); err != nil {
+		t.Errorf("transactions following snapshot do not extend bootstrapped chain (%v)", err)
+	}
+
+	if err := fresh.BootstrapFromSnapshot(headers, s, ch.WitnessReputation); err == nil {
+		t.Errorf("non-empty chain bootstrapped")
+	}
+}
+
+func TestCoSignSnapshot(t *testing.T) {
+	key, otherKey := testKey(t), testKey(t)
+	ch := testCheckpointedChain(t, key, 10, 5)
+	witnesses := []discovery.NodeID{discovery.PubkeyToNodeID(&key.PublicKey), discovery.PubkeyToNodeID(&otherKey.PublicKey)}
+
+	// Second witness holds same transactions & checkpoints, but has its own view of peer reputation
+	db, err := discovery.NewNodeDatabase(witnesses[1], "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.AddPeer(&discovery.Peer{ID: witnesses[0], Addresses: []string{"1.1.1.1"}})
+	db.RecordContact(witnesses[0], time.Millisecond)
+
+	other := *ch
+	other.NodeDb = db
+
+	s, err := ch.NewSnapshot(nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherS, err := other.NewSnapshot(nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.Reputation) == len(otherS.Reputation) {
+		t.Fatalf("witnesses expected to differ in recorded reputation")
+	}
+
+	if err := s.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := otherS.Sign(otherKey); err != nil {
+		t.Fatal(err)
+	}
+
+	cosigned := *s
+	cosigned.Signatures = append(append([]CheckpointSignature{}, s.Signatures...), otherS.Signatures...)
+
+	trusted := func(id discovery.NodeID) int {
+		if id == witnesses[0] || id == witnesses[1] {
+			return Checkpointing.MinReputation
+		}
+
+		return 0
+	}
+
+	policy := Checkpointing
+	policy.MinSignatures = 2
+
+	tests := []struct {
+		name string
+		s    *Snapshot
+		err  error
+	}{
+		{"co-signed", &cosigned, nil},
+		{"single witness", s, ErrInsufficientSignatures},
+	}
+
+	for _, test := range tests {
+		if err := test.s.Verify(ch.LatestCheckpoint(), trusted, policy); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
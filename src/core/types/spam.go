@@ -0,0 +1,114 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+)
+
+// MaxWorkDifficulty - highest proof-of-work difficulty chain may require
+const MaxWorkDifficulty = 64
+
+var (
+	// ErrInsufficientWork - returned when transaction proof-of-work does not meet chain difficulty
+	ErrInsufficientWork = errors.New("transaction proof-of-work below chain difficulty")
+
+	// ErrRateLimited - returned when sending account exceeded chain's transaction rate limit
+	ErrRateLimited = errors.New("sending account exceeded transaction rate limit")
+)
+
+// SpamPolicy - per-chain protection against floods of fee-less transactions; zero value disables both checks
+type SpamPolicy struct {
+	Difficulty uint8 `json:"difficulty"` // Leading zero bits required in transaction work hash
+
+	RateLimit  int           `json:"ratelimit"`  // Maximum transactions per sending account within RateWindow
+	RateWindow time.Duration `json:"ratewindow"` // Period rate limit applies to
+}
+
+// WorkHash - hash transaction proof-of-work is measured on; covers signed fields (including Extra,
+// which holds work nonce)
+func (tx *Transaction) WorkHash() common.Hash {
+	signing := tx.SigningHash()
+	sum := sha256.Sum256(signing[:])
+
+	return common.BytesToHash(sum[:])
+}
+
+// WorkBits - proof-of-work of transaction, as number of leading zero bits in work hash
+func (tx *Transaction) WorkBits() int {
+	hash := tx.WorkHash()
+
+	for x, b := range hash {
+		if b != 0 {
+			return x*8 + bits.LeadingZeros8(b)
+		}
+	}
+
+	return common.HashLength * 8
+}
+
+// SolveWork - append work nonce to transaction Extra until work meets difficulty; as Extra is
+// covered by sender's signature, work must be solved before transaction is signed
+func (tx *Transaction) SolveWork(difficulty uint8) error {
+	if difficulty > MaxWorkDifficulty {
+		return errors.New("work difficulty too high")
+	}
+
+	base := tx.Data.Extra
+	extra := make([]byte, len(base)+8)
+	copy(extra, base)
+
+	tx.Data.Extra = extra
+
+	for nonce := uint64(0); tx.WorkBits() < int(difficulty); nonce++ {
+		binary.BigEndian.PutUint64(extra[len(base):], nonce)
+	}
+
+	tx.rehash()
+
+	return nil
+}
+
+// CheckSpam - check transaction against chain's spam policy; pending is the number of transactions
+// from same sender received within rate window & awaiting inclusion in chain (e.g. held in mempool),
+// counted towards rate limit. Rate limit is only applied once sender is authenticated, so forged
+// senders cannot exhaust limit of other accounts, & window always ends at local time, never at
+// witness time carried by transaction, which sender controls.
+func (RefChain Chain) CheckSpam(tx *Transaction, pending int) error {
+	policy := RefChain.SpamPolicy
+
+	if policy.Difficulty > 0 && tx.WorkBits() < int(policy.Difficulty) {
+		return ErrInsufficientWork
+	}
+
+	if policy.RateLimit > 0 {
+		err := tx.Verify()
+
+		if err != nil {
+			return err
+		}
+
+		if RefChain.countSentSince(tx.SendingAccount.Address, time.Now().UTC().Add(-policy.RateWindow))+pending >= policy.RateLimit {
+			return ErrRateLimited
+		}
+	}
+
+	return nil
+}
+
+// countSentSince - number of transactions in chain sent by account & witnessed after specified time
+func (RefChain Chain) countSentSince(addr common.Address, since time.Time) int {
+	count := 0
+
+	for _, tx := range RefChain.Transactions {
+		if tx != nil && tx.SendingAccount.Address == addr && tx.InitialWitness != nil && tx.InitialWitness.WitnessTime.After(since) {
+			count++
+		}
+	}
+
+	return count
+}
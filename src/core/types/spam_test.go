@@ -0,0 +1,93 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+)
+
+func TestSolveWork(t *testing.T) {
+	key := testKey(t)
+
+	tx := NewTransaction(1, *NewAccount(PubkeyToAddress(&key.PublicKey)), common.HexToAddress("02"), NewAmount(1), nil, nil, []byte{0x01})
+
+	if err := tx.SolveWork(MaxWorkDifficulty + 1); err == nil {
+		t.Errorf("excessive difficulty accepted")
+	}
+
+	if err := tx.SolveWork(8); err != nil || tx.WorkBits() < 8 || tx.Data.Extra[0] != 0x01 {
+		t.Fatalf("work not solved (%v)", err)
+	}
+
+	if err := tx.SignWith(key); err != nil || tx.WorkBits() < 8 {
+		t.Errorf("work lost when signing (%v)", err)
+	}
+}
+
+func TestCheckSpam(t *testing.T) {
+	key := testKey(t)
+	policy := SpamPolicy{Difficulty: 8, RateLimit: 2, RateWindow: time.Hour}
+
+	unsolved := testTransaction(t, key, 0, common.HexToAddress("02"), NewAmount(1))
+
+	for unsolved.WorkBits() >= 8 {
+		unsolved.Data.Nonce += 100 // Make sure transaction starts without sufficient work
+	}
+
+	if err := unsolved.SignWith(key); err != nil {
+		t.Fatal(err)
+	}
+
+	solved := func(nonce uint64) *Transaction {
+		tx := NewTransaction(nonce, *NewAccount(PubkeyToAddress(&key.PublicKey)), common.HexToAddress("02"), NewAmount(1), nil, nil, nil)
+		tx.SolveWork(8)
+
+		if err := tx.SignWith(key); err != nil {
+			t.Fatal(err)
+		}
+
+		witness := NewWitness(1000, HexToSignature("01"), 100)
+		tx.InitialWitness = &witness
+
+		return tx
+	}
+
+	future := solved(3)
+	future.InitialWitness.WitnessTime = time.Now().UTC().Add(2 * time.Hour) // Shifting rate window past sent transactions
+
+	forged := solved(3)
+	forged.SendingAccount = *NewAccount(common.HexToAddress("03")) // Exhausting rate limit of other account
+
+	empty := &Chain{SpamPolicy: policy}
+
+	sent := &Chain{SpamPolicy: policy}
+	sent.AddTransaction(solved(1))
+
+	full := &Chain{SpamPolicy: policy}
+	full.AddTransaction(solved(1))
+	full.AddTransaction(solved(2))
+
+	tests := []struct {
+		name    string
+		ch      *Chain
+		tx      *Transaction
+		pending int
+		err     error
+	}{
+		{"without work", empty, unsolved, 0, ErrInsufficientWork},
+		{"with work", empty, solved(3), 0, nil},
+		{"below rate limit", sent, solved(3), 0, nil},
+		{"pending counted towards rate limit", sent, solved(3), 1, ErrRateLimited},
+		{"over rate limit", full, solved(3), 0, ErrRateLimited},
+		{"witnessed in future", full, future, 0, ErrRateLimited},
+		{"forged sender", &Chain{SpamPolicy: SpamPolicy{RateLimit: 2, RateWindow: time.Hour}}, forged, 0, ErrHashMismatch},
+		{"policy disabled", &Chain{}, unsolved, 5, nil},
+	}
+
+	for _, test := range tests {
+		if err := test.ch.CheckSpam(test.tx, test.pending); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
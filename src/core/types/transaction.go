@@ -2,14 +2,12 @@ package types
 
 import (
 	"bytes"
-	"crypto"
 	"encoding/json"
-	// crypto/sha256 - required for hashing functions
-	_ "crypto/sha256"
 	"fmt"
 	"sync/atomic"
 	"time"
 
+	"github.com/mitsukomegumi/indo-go/src/common"
 	contracts "github.com/mitsukomegumi/indo-go/src/contracts"
 )
 
@@ -26,6 +24,9 @@ type Transaction struct {
 
 	SendingAccount Account `json:"sending account"`
 
+	Signature Signature `json:"signature,omitempty"` // Sender's signature of signing hash
+	PublicKey []byte    `json:"pubkey,omitempty"`    // Sender's public key (uncompressed); must hash to sending address
+
 	ChainVersion int `json:"chainver"`
 
 	hash atomic.Value
@@ -35,50 +36,56 @@ type Transaction struct {
 
 type transactiondata struct {
 	// Initialized in func:
-	Nonce     uint64    `json:"nonce" gencodec:"required"`
-	Recipient *Address  `json:"recipient"`
-	Amount    *int      `json:"value" gencodec:"required"`
-	Payload   []byte    `json:"payload" gencodec:"required"`
-	Time      time.Time `json:"timestamp" gencodec:"required"`
-	Extra     []byte    `json:"extraData" gencodec:"required"`
+	Nonce     uint64          `json:"nonce" gencodec:"required"`
+	Recipient *common.Address `json:"recipient"`
+	Amount    Amount          `json:"value" gencodec:"required"`
+	Payload   []byte          `json:"payload" gencodec:"required"`
+	Time      time.Time       `json:"timestamp" gencodec:"required"`
+	Extra     []byte          `json:"extraData" gencodec:"required"`
 
 	// Initialized at intercept:
-	InitialHash *Hash `json:"hash" gencodec:"required"`
-	ParentHash  *Hash `json:"parentHash" gencodec:"required"`
+	InitialHash *common.Hash `json:"hash" gencodec:"required"`
+	ParentHash  *common.Hash `json:"parentHash" gencodec:"required"`
 }
 
 //NewTransaction - Create new instance of transaction struct with specified arguments.
-func NewTransaction(nonce uint64, SendingAccount Account, to Address, amount *int, data []byte, contract *contracts.Contract, extra []byte) *Transaction {
+func NewTransaction(nonce uint64, SendingAccount Account, to common.Address, amount Amount, data []byte, contract *contracts.Contract, extra []byte) *Transaction {
 	return newTransaction(nonce, SendingAccount, &to, amount, data, contract, extra)
 }
 
 //NewContractCreation - Create new instance of transaction struct specifying contract creation arguments.
-func NewContractCreation(nonce uint64, IssuingAccount Account, amount *int, data []byte, extra []byte) *Transaction {
+func NewContractCreation(nonce uint64, IssuingAccount Account, amount Amount, data []byte, extra []byte) *Transaction {
 	return newTransaction(nonce, IssuingAccount, nil, amount, data, nil, extra)
 }
 
-func newTransaction(nonce uint64, from Account, to *Address, amount *int, data []byte, contract *contracts.Contract, extra []byte) *Transaction {
-	hash := crypto.SHA256.New()
+func newTransaction(nonce uint64, from Account, to *common.Address, amount Amount, data []byte, contract *contracts.Contract, extra []byte) *Transaction {
 	txdata := transactiondata{
-		Nonce:       nonce,
-		Recipient:   to,
-		Payload:     data,
-		Amount:      new(int),
-		Time:        time.Now().UTC(),
-		Extra:       extra,
-		InitialHash: &Hash{},
+		Nonce:     nonce,
+		Recipient: to,
+		Payload:   data,
+		Amount:    amount,
+		Time:      time.Now().UTC(),
+		Extra:     extra,
 	}
 
-	s := fmt.Sprintf("%v", txdata)
-	bArray := hash.Sum([]byte(s))
+	tx := &Transaction{Data: txdata, Contract: contract, Weight: int(0), Verifications: int(0), SendingAccount: from}
+	tx.rehash()
 
-	*txdata.InitialHash = BytesToHash(bArray)
+	return tx
+}
 
-	if amount != nil {
-		txdata.Amount = amount
-	}
+// rehash - set transaction hash to signing hash of its current contents
+func (tx *Transaction) rehash() {
+	hash := tx.SigningHash()
+	tx.Data.InitialHash = &hash
+}
 
-	return &Transaction{Data: txdata, Contract: contract, Weight: int(0), Verifications: int(0), SendingAccount: from}
+// Hash - return hash identifying transaction; equals signing hash of valid transactions (see VerifyHash)
+func (tx *Transaction) Hash() common.Hash {
+	if tx.Data.InitialHash == nil {
+		return common.Hash{}
+	}
+	return *tx.Data.InitialHash
 }
 
 // DecodeTxFromBytes - decode transaction from specified byte array, returning transaction
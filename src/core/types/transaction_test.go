@@ -0,0 +1,92 @@
+package types
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+)
+
+func TestTransactionEncoding(t *testing.T) {
+	key := testKey(t)
+	tx := testTransaction(t, key, 1, common.HexToAddress("02"), NewAmount(1))
+
+	b, err := json.Marshal(tx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := Transaction{}
+
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Hash() != tx.Hash() || *decoded.Data.Recipient != *tx.Data.Recipient || decoded.VerifySignature() != nil {
+		t.Errorf("transaction did not round trip: %s", b)
+	}
+}
+
+func TestVerifyTransaction(t *testing.T) {
+	key, other := testKey(t), testKey(t)
+
+	valid := testTransaction(t, key, 1, common.HexToAddress("02"), NewAmount(1))
+
+	unsigned := NewTransaction(1, *NewAccount(PubkeyToAddress(&key.PublicKey)), common.HexToAddress("02"), NewAmount(1), nil, nil, nil)
+
+	forgedSender := *valid
+	forgedSender.SendingAccount = *NewAccount(PubkeyToAddress(&other.PublicKey))
+
+	rehashedSender := forgedSender
+	rehashedSender.rehash()
+
+	forgedAmount := *valid
+	forgedAmount.Data.Amount = NewAmount(1000)
+	forgedAmount.rehash()
+
+	forgedHash := *valid
+	forgedHash.Data.InitialHash = &common.Hash{}
+
+	tests := []struct {
+		name string
+		tx   *Transaction
+		err  error
+	}{
+		{"valid", valid, nil},
+		{"unsigned", unsigned, ErrUnsigned},
+		{"forged sender", &forgedSender, ErrHashMismatch},
+		{"forged sender, rehashed", &rehashedSender, ErrInvalidSignature},
+		{"forged amount", &forgedAmount, ErrInvalidSignature},
+		{"forged hash", &forgedHash, ErrHashMismatch},
+	}
+
+	for _, test := range tests {
+		if err := test.tx.Verify(); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestSigningHashOptionalFields(t *testing.T) {
+	sender := *NewAccount(PubkeyToAddress(&testKey(t).PublicKey))
+
+	field := func(b []byte) []byte {
+		return append(binary.BigEndian.AppendUint32(nil, uint32(len(b))), b...)
+	}
+
+	// Contract creation without recipient, whose leading fields read as recipient of transfer below
+	amount, transferAmount, transferPayload := NewAmount(1), NewAmount(2), []byte("transfer")
+	payload := append(bytes.Repeat([]byte{0xaa}, 11), append(field([]byte(transferAmount.String())), field(transferPayload)...)...)
+	creation := NewContractCreation(1, sender, amount, payload, nil)
+
+	recipient := append(field([]byte(amount.String())), field(payload)...)[:common.AddressLength]
+	transfer := NewTransaction(1, sender, common.BytesToAddress(recipient), transferAmount, transferPayload, nil, nil)
+	transfer.Data.Time = creation.Data.Time
+
+	if creation.SigningHash() == transfer.SigningHash() {
+		t.Errorf("transaction without recipient hashes like transaction with recipient")
+	}
+}
@@ -0,0 +1,152 @@
+package types
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+)
+
+var (
+	// ErrUnsigned - returned when verifying transaction without signature
+	ErrUnsigned = errors.New("transaction not signed")
+
+	// ErrInvalidSignature - returned when transaction signature does not match sender
+	ErrInvalidSignature = errors.New("invalid transaction signature")
+
+	// ErrHashMismatch - returned when transaction hash does not match transaction contents
+	ErrHashMismatch = errors.New("transaction hash does not match contents")
+)
+
+// PubkeyToAddress - derive account address from public key (last 20 bytes of SHA-256 of X || Y)
+func PubkeyToAddress(pub *ecdsa.PublicKey) common.Address {
+	var xy [64]byte
+	pub.X.FillBytes(xy[:32])
+	pub.Y.FillBytes(xy[32:])
+
+	sum := sha256.Sum256(xy[:])
+
+	return common.BytesToAddress(sum[12:])
+}
+
+// SigningHash - hash of transaction fields covered by sender's signature
+func (tx *Transaction) SigningHash() common.Hash {
+	hash := sha256.New()
+
+	var nonce [8]byte
+	binary.BigEndian.PutUint64(nonce[:], tx.Data.Nonce)
+
+	hash.Write(nonce[:])
+	hash.Write(tx.SendingAccount.Address[:])
+
+	if writePresence(hash, tx.Data.Recipient != nil) {
+		hash.Write(tx.Data.Recipient[:])
+	}
+
+	writeField(hash, []byte(tx.Data.Amount.String()))
+	writeField(hash, tx.Data.Payload)
+	writeField(hash, []byte(tx.Data.Time.UTC().Format(time.RFC3339Nano)))
+	writeField(hash, tx.Data.Extra)
+
+	if writePresence(hash, tx.Contract != nil) {
+		writeField(hash, tx.Contract.Identifier)
+	}
+
+	return common.BytesToHash(hash.Sum(nil))
+}
+
+// writeField - write length-prefixed field to hash, so adjacent fields cannot be shifted into one another
+func writeField(hash interface{ Write([]byte) (int, error) }, b []byte) {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(b)))
+
+	hash.Write(length[:])
+	hash.Write(b)
+}
+
+// writePresence - write marker of whether optional field is present to hash, so absent field cannot be
+// confused with content of following field; returns presence
+func writePresence(hash interface{ Write([]byte) (int, error) }, present bool) bool {
+	marker := []byte{0}
+
+	if present {
+		marker[0] = 1
+	}
+
+	hash.Write(marker)
+
+	return present
+}
+
+// SignWith - sign transaction with sender's private key; key must belong to sending account
+func (tx *Transaction) SignWith(key *ecdsa.PrivateKey) error {
+	if PubkeyToAddress(&key.PublicKey) != tx.SendingAccount.Address {
+		return errors.New("signing key does not belong to sending account")
+	}
+
+	hash := tx.SigningHash()
+
+	sig, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+
+	if err != nil {
+		return err
+	}
+
+	tx.Signature = sig
+	tx.PublicKey = elliptic.Marshal(elliptic.P256(), key.PublicKey.X, key.PublicKey.Y)
+	tx.rehash()
+
+	return nil
+}
+
+// Verify - check that transaction hash matches its contents & transaction is signed by sender
+func (tx *Transaction) Verify() error {
+	err := tx.VerifyHash()
+
+	if err != nil {
+		return err
+	}
+
+	return tx.VerifySignature()
+}
+
+// VerifyHash - check that transaction hash is signing hash of its contents
+func (tx *Transaction) VerifyHash() error {
+	if tx.Data.InitialHash == nil || *tx.Data.InitialHash != tx.SigningHash() {
+		return ErrHashMismatch
+	}
+
+	return nil
+}
+
+// VerifySignature - check that transaction is signed by holder of sending account's key
+func (tx *Transaction) VerifySignature() error {
+	if len(tx.Signature) == 0 || len(tx.PublicKey) == 0 {
+		return ErrUnsigned
+	}
+
+	x, y := elliptic.Unmarshal(elliptic.P256(), tx.PublicKey)
+
+	if x == nil {
+		return ErrInvalidSignature
+	}
+
+	pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+
+	if PubkeyToAddress(pub) != tx.SendingAccount.Address {
+		return ErrInvalidSignature
+	}
+
+	hash := tx.SigningHash()
+
+	if !ecdsa.VerifyASN1(pub, hash[:], tx.Signature) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
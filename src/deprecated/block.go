@@ -4,24 +4,10 @@ import (
 	"github.com/mitsukomegumi/indo-go/src/core/types"
 )
 
-// Nonce - hash symbolizing amount of processing power required to produce block
-type Nonce [8]byte
-
-// Header -
-type Header struct {
-	ParentHash   types.Hash    `json:"parentHash"       gencodec:"required"`
-	MinerAddress types.Address `json:"miner"            gencodec:"required"`
-	TxHash       types.Hash    `json:"transactionsRoot" gencodec:"required"`
-	Difficulty   *int          `json:"difficulty"       gencodec:"required"`
-	Time         *int          `json:"timestamp"        gencodec:"required"`
-	Extra        []byte        `json:"extraData"        gencodec:"required"`
-	Nonce        Nonce         `json:"nonce"            gencodec:"required"`
-}
-
 // Body - container representing data of block
 type Body struct {
 	Transactions []*types.Transaction
-	Uncles       []*Header
+	Uncles       []*types.Header // Block headers are superseded by chain checkpoints (types.Header)
 }
 
 /*
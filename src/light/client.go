@@ -0,0 +1,268 @@
+package light
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/core/token"
+	"github.com/mitsukomegumi/indo-go/src/core/types"
+	"github.com/mitsukomegumi/indo-go/src/networking"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+)
+
+// headerRetries - number of consecutive failed header requests after which header sync is abandoned
+const headerRetries = 3
+
+var (
+	// ErrUnknownCheckpoint - returned when verifying proof against checkpoint not yet synced
+	ErrUnknownCheckpoint = errors.New("proof refers to checkpoint not synced by light client")
+
+	// ErrUnrelatedTransaction - returned when account proof contains transaction not involving account
+	ErrUnrelatedTransaction = errors.New("account proof contains transaction of other account")
+)
+
+// Client - light client of single chain; holds only verified checkpoint headers & verifies
+// transactions & account state served by full nodes against them
+type Client struct {
+	ChainID common.Identifier
+	Headers []*types.Header // Verified checkpoint headers, oldest first
+
+	Db         *discovery.NodeDatabase
+	Reputation types.ReputationFunc // Reputation of checkpoint witnesses; defaults to trusting configured witnesses
+
+	path string
+}
+
+// Account - account state derived by light client from transactions proven to be part of chain; transactions
+// withheld by serving node are not detected, so state is not proven complete
+type Account struct {
+	Address  common.Address `json:"address"`
+	Received types.Amount   `json:"received"`
+	Sent     types.Amount   `json:"sent"`
+	Nonce    uint64         `json:"nonce"`      // Nonce of next transaction sent from account
+	Version  int            `json:"version"`    // Last chain version transactions are proven up to
+	Checked  int            `json:"checkpoint"` // Number of checkpoint transactions are proven against
+}
+
+// HeadersPath - file headers of chain with specified identifier are stored in under specified path
+func HeadersPath(path string, id common.Identifier) string {
+	return path + id.String() + "Headers.gob"
+}
+
+// NewClient - light client of chain with specified identifier, resuming from headers stored under
+// path if present
+func NewClient(path string, id common.Identifier, Db *discovery.NodeDatabase) *Client {
+	client := &Client{ChainID: id, Db: Db, path: path}
+
+	var headers []*types.Header
+
+	if common.ReadGob(HeadersPath(path, id), &headers) == nil {
+		client.Headers = headers
+	}
+
+	return client
+}
+
+// Latest - most recent verified checkpoint header (nil if none)
+func (client *Client) Latest() *types.Header {
+	if len(client.Headers) == 0 {
+		return nil
+	}
+
+	return client.Headers[len(client.Headers)-1]
+}
+
+// Header - verified checkpoint header with specified number (nil if not synced)
+func (client *Client) Header(number int) *types.Header {
+	if number < 1 || number > len(client.Headers) {
+		return nil
+	}
+
+	return client.Headers[number-1]
+}
+
+// SyncHeaders - fetch & verify checkpoint headers following latest verified header from best node,
+// writing them to memory after each batch
+func (client *Client) SyncHeaders() error {
+	node := client.Db.FindNode()
+	failures := 0
+
+	for {
+		batch, err := networking.RequestHeaders(client.Db, node, client.ChainID, len(client.Headers), networking.HeaderBatchSize)
+
+		if err != nil {
+			failures++
+
+			if failures > headerRetries {
+				return err
+			}
+
+			continue
+		}
+
+		failures = 0
+
+		err = client.AddHeaders(batch.Headers)
+
+		if err == types.ErrInsufficientSignatures {
+			return err // Not enough reputable witnesses known yet; not misbehavior of serving node
+		} else if err != nil {
+			client.Db.Misbehave(node, client.Db.PeerID(node), discovery.ViolationInvalidChain)
+			return err
+		}
+
+		err = common.WriteGob(HeadersPath(client.path, client.ChainID), client.Headers)
+
+		if err != nil {
+			return err
+		}
+
+		common.ThrowSuccess("synced " + strconv.Itoa(len(client.Headers)) + " checkpoint headers")
+
+		if !batch.More || len(batch.Headers) == 0 {
+			return nil
+		}
+	}
+}
+
+// AddHeaders - verify headers extend latest verified header & are signed by reputable witnesses, then add them
+func (client *Client) AddHeaders(headers []*types.Header) error {
+	err := types.VerifyHeaders(client.Latest(), headers, client.reputation(), types.Checkpointing)
+
+	if err != nil {
+		return err
+	}
+
+	client.Headers = append(client.Headers, headers...)
+
+	return nil
+}
+
+// VerifyProof - verify inclusion proof of transaction against synced checkpoint header; proven leaf
+// is recomputed from transaction contents, never taken from hash carried by transaction
+func (client *Client) VerifyProof(tx *types.Transaction, proof *types.TxProof) error {
+	if proof == nil || tx == nil {
+		return errors.New("proof does not refer to transaction")
+	}
+
+	h := client.Header(proof.Checkpoint)
+
+	if h == nil {
+		return ErrUnknownCheckpoint
+	}
+
+	if tx.ChainVersion < h.FromVersion || tx.ChainVersion > h.ToVersion {
+		return errors.New("transaction version outside checkpoint range")
+	}
+
+	return proof.Verify(tx, h)
+}
+
+// Transaction - request transaction with specified hash from best node, verifying its inclusion proof
+func (client *Client) Transaction(hash common.Hash) (*types.Transaction, error) {
+	tx, proof, err := networking.RequestProof(client.Db, client.Db.FindNode(), client.ChainID, hash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = client.VerifyProof(tx, proof)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// Account - request account's transactions from best node, verifying each against synced headers
+// & deriving account state from them
+func (client *Client) Account(addr common.Address) (*Account, error) {
+	resp, err := networking.RequestAccount(client.Db, client.Db.FindNode(), client.ChainID, addr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return client.VerifyAccount(addr, resp.Transactions, resp.Proofs)
+}
+
+// VerifyAccount - verify transactions of account & their proofs, deriving account state from them;
+// proofs show every transaction is part of chain, but completeness of transactions is not proven
+func (client *Client) VerifyAccount(addr common.Address, txs []*types.Transaction, proofs []*types.TxProof) (*Account, error) {
+	if len(txs) != len(proofs) {
+		return nil, errors.New("account proof without proof for every transaction")
+	}
+
+	received, sent := new(big.Int), new(big.Int)
+	account := &Account{Address: addr}
+	issues := token.Issuance(client.ChainID)
+
+	if latest := client.Latest(); latest != nil {
+		account.Checked, account.Version = latest.Number, latest.ToVersion
+	}
+
+	for x, tx := range txs {
+		if tx == nil || !tx.Involves(addr) {
+			return nil, ErrUnrelatedTransaction
+		}
+
+		err := client.VerifyProof(tx, proofs[x])
+
+		if err != nil {
+			return nil, err
+		}
+
+		if tx.Data.Recipient != nil && *tx.Data.Recipient == addr {
+			received.Add(received, tx.Data.Amount.Units())
+		}
+
+		if tx.SendingAccount.Address == addr {
+			if !issues(tx) {
+				sent.Add(sent, tx.Data.Amount.Units())
+			}
+
+			if tx.Data.Nonce >= account.Nonce {
+				account.Nonce = tx.Data.Nonce + 1
+			}
+		}
+	}
+
+	var err error
+
+	account.Received, err = types.AmountFromUnits(received)
+
+	if err != nil {
+		return nil, err
+	}
+
+	account.Sent, err = types.AmountFromUnits(sent)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// Balance - balance of account, as received minus sent amount
+func (account *Account) Balance() (types.Amount, error) {
+	return account.Received.Sub(account.Sent)
+}
+
+func (client *Client) reputation() types.ReputationFunc {
+	if client.Reputation != nil {
+		return client.Reputation
+	}
+
+	return func(id discovery.NodeID) int {
+		if types.Checkpointing.IsWitness(id) {
+			return math.MaxInt32
+		}
+
+		return 0
+	}
+}
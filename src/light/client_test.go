@@ -0,0 +1,149 @@
+package light
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/core/types"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+)
+
+// testChain - chain of count transactions of 2 from account of key to recipient, checkpointed
+// once by witness node of key
+func testChain(t *testing.T, key *ecdsa.PrivateKey, recipient common.Address, count int) *types.Chain {
+	ch := &types.Chain{NodeDb: &discovery.NodeDatabase{SelfRef: discovery.PubkeyToNodeID(&key.PublicKey)}}
+	sender := types.NewAccount(types.PubkeyToAddress(&key.PublicKey))
+
+	for x := 0; x < count; x++ {
+		tx := types.NewTransaction(uint64(x), *sender, recipient, types.NewAmount(2), nil, nil, nil)
+
+		if err := tx.SignWith(key); err != nil {
+			t.Fatal(err)
+		}
+
+		ch.AddTransaction(tx)
+	}
+
+	h, err := ch.NewCheckpoint()
+
+	if err == nil {
+		err = h.Sign(key)
+	}
+
+	if err == nil {
+		err = ch.AddCheckpoint(h, ch.WitnessReputation)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ch
+}
+
+// testClient - light client of chain, trusting chain's witnesses
+func testClient(t *testing.T, ch *types.Chain) *Client {
+	dir, err := ioutil.TempDir("", "light")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	client := NewClient(dir+string(os.PathSeparator), ch.Identifier, nil)
+	client.Reputation = ch.WitnessReputation
+
+	return client
+}
+
+func TestAddHeaders(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ch := testChain(t, key, common.HexToAddress("08"), 10)
+	client := testClient(t, ch)
+
+	forged := *ch.Checkpoints[0]
+	forged.TxRoot = common.Hash{}
+
+	if client.AddHeaders([]*types.Header{&forged}) == nil {
+		t.Errorf("checkpoint with invalid signature accepted")
+	}
+
+	if err := client.AddHeaders(ch.Checkpoints); err != nil || client.Latest() != ch.Checkpoints[0] {
+		t.Fatalf("valid checkpoints rejected (%v)", err)
+	}
+
+	if client.AddHeaders(ch.Checkpoints) == nil {
+		t.Errorf("checkpoint added twice")
+	}
+}
+
+func TestVerifyAccount(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	sender, recipient := types.PubkeyToAddress(&key.PublicKey), common.HexToAddress("08")
+
+	ch := testChain(t, key, recipient, 10)
+	client := testClient(t, ch)
+
+	if err := client.AddHeaders(ch.Checkpoints); err != nil {
+		t.Fatal(err)
+	}
+
+	txs, proofs, err := ch.ProveAccount(recipient)
+
+	if err != nil || len(txs) != 10 {
+		t.Fatalf("account proof incomplete: %d transactions (%v)", len(txs), err)
+	}
+
+	account, err := client.VerifyAccount(recipient, txs, proofs)
+
+	if err != nil {
+		t.Fatalf("account proof rejected (%v)", err)
+	}
+
+	if balance, _ := account.Balance(); balance.Cmp(types.NewAmount(20)) != 0 {
+		t.Errorf("wrong balance %s", balance)
+	}
+
+	if sent, _ := client.VerifyAccount(sender, txs, proofs); sent == nil || sent.Nonce != 10 {
+		t.Errorf("wrong nonce of sender")
+	}
+
+	swapped := append([]*types.TxProof{proofs[1], proofs[0]}, proofs[2:]...)
+
+	// Transaction carrying hash of checkpointed transaction, but crediting larger amount
+	inflated := *txs[0]
+	inflated.Data.Amount = types.NewAmount(1000)
+	forged := append([]*types.Transaction{&inflated}, txs[1:]...)
+
+	tests := []struct {
+		name   string
+		addr   common.Address
+		txs    []*types.Transaction
+		proofs []*types.TxProof
+		err    error
+	}{
+		{"other account", common.HexToAddress("09"), txs, proofs, ErrUnrelatedTransaction},
+		{"mismatched proofs", recipient, txs, swapped, nil},
+		{"missing proof", recipient, txs, proofs[1:], nil},
+		{"forged leaf hash", recipient, forged, proofs, nil},
+	}
+
+	for _, test := range tests {
+		if _, err := client.VerifyAccount(test.addr, test.txs, test.proofs); err == nil || (test.err != nil && err != test.err) {
+			t.Errorf("%s: accepted or unexpected error (%v)", test.name, err)
+		}
+	}
+
+	unsynced := *proofs[0]
+	unsynced.Checkpoint = 2
+
+	if err := client.VerifyProof(txs[0], &unsynced); err != ErrUnknownCheckpoint {
+		t.Errorf("proof against unsynced checkpoint accepted (%v)", err)
+	}
+}
@@ -2,30 +2,59 @@ package main
 
 import (
 	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/mitsukomegumi/indo-go/src/common"
 	"github.com/mitsukomegumi/indo-go/src/consensus"
 	"github.com/mitsukomegumi/indo-go/src/contracts"
+	"github.com/mitsukomegumi/indo-go/src/core/token"
 	"github.com/mitsukomegumi/indo-go/src/core/types"
+	"github.com/mitsukomegumi/indo-go/src/light"
 	"github.com/mitsukomegumi/indo-go/src/networking"
 	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
-	upnp "github.com/nebulouslabs/go-upnp"
+	"github.com/mitsukomegumi/indo-go/src/wallet"
 )
 
 var relayFlag = flag.Bool("relay", false, "relay tx to node")
 var listenFlag = flag.Bool("listen", false, "listen for transaction relays")
 var hostFlag = flag.Bool("host", false, "host current copy of chain")
-var fetchFlag = flag.Bool("fetch", false, "fetch current copy of chain")
+var fetchFlag = flag.Bool("fetch", false, "sync local copy of chain (fetches full chain if none exists)")
 var newChainFlag = flag.Bool("new", false, "create new chain")
+var newWalletFlag = flag.Bool("newwallet", false, "create wallet ("+wallet.WalletFile+") encrypted with $"+walletPasswordEnv)
+var toFlag = flag.String("to", "", "address test transaction is sent to")
+var newTokenFlag = flag.String("newtoken", "", "create token with own chain, specified as name:symbol:supply")
 var loopFlag = flag.Bool("forever", false, "perform indefinitely")
 var fullChainFlag = flag.Bool("relaychain", false, "relay entire chain")
 var registerNode = flag.Bool("regnode", false, "registers node")
-var noUpNPFlag = flag.Bool("noupnp", false, "used for nodes without upnp")
+var noUpNPFlag = flag.Bool("noupnp", false, "used for nodes without nat traversal (same as --nat none)")
+var natFlag = flag.String("nat", "any", "nat traversal method (none, any, upnp, pmp, pmp:<gateway ip>, extip:<external ip>)")
+var bootstrapFlag = flag.String("bootstrap", "", "comma-separated bootstrap node addresses (overrides "+discovery.BootstrapConfigFile+" & $"+discovery.BootstrapEnv+")")
+var networkFlag = flag.String("network", discovery.MainNetwork, "network to join (selects bootstrap nodes & dns seeds)")
+var chainFlag = flag.String("chain", "", "hex identifier of chain to operate on (default chain if empty)")
+var difficultyFlag = flag.Uint("difficulty", 0, "proof-of-work difficulty (leading zero bits) required of transactions on created chain")
+var rateLimitFlag = flag.Int("ratelimit", 0, "maximum transactions per account within --ratewindow on created chain (0 disables)")
+var rateWindowFlag = flag.Duration("ratewindow", time.Hour, "period --ratelimit applies to")
+var lightFlag = flag.Bool("light", false, "run as light client (syncs & verifies checkpoint headers only)")
+var accountFlag = flag.String("account", "", "address whose balance light client derives from proven transactions")
+var txFlag = flag.String("tx", "", "hex hash of transaction whose inclusion light client verifies")
+var pruneFlag = flag.Bool("prune", false, "drop transactions covered by state snapshots")
+var witnessesFlag = flag.String("witnesses", "", "comma-separated hex node ids of witnesses trusted to sign checkpoints & snapshots")
+var allowPrivateFlag = flag.Bool("allowprivate", false, "accept nodes with private addresses (always set on test network)")
+
+// walletPasswordEnv - environment variable holding wallet password
+const walletPasswordEnv = "INDO_WALLET_PASSWORD"
 
 /*
 	TODO:
@@ -37,17 +66,45 @@ var noUpNPFlag = flag.Bool("noupnp", false, "used for nodes without upnp")
 func main() {
 	flag.Parse()
 
+	discovery.Bootstrap = discovery.LoadBootstrapConfig(*networkFlag, common.GetCurrentDir()+discovery.BootstrapConfigFile, *bootstrapFlag)
+	discovery.Addressing.AllowPrivate = *allowPrivateFlag || *networkFlag == discovery.TestNetwork
+	types.Snapshotting.Prune = *pruneFlag
+
+	witnesses, err := types.ParseWitnesses(*witnessesFlag)
+
+	if err != nil {
+		panic(err)
+	}
+
+	types.Checkpointing.Witnesses = witnesses
+
+	if *networkFlag == discovery.TestNetwork {
+		common.AddressPrefix = common.TestAddressPrefix
+	}
+
+	chainID, err := common.HexToIdentifier(*chainFlag)
+
+	if err != nil {
+		panic(err)
+	}
+
 	if *relayFlag || *listenFlag || *hostFlag || *fetchFlag || *loopFlag || *fullChainFlag || *noUpNPFlag {
+		var mapping *networking.PortMapping
+
 		if *listenFlag || *hostFlag {
 			common.ThrowWarning("starting host")
 
-			var gd *upnp.IGD
+			var natm networking.NATManager
 
 			var err error
 
 			if !*noUpNPFlag {
-				common.ThrowWarning("attempting to connect to gateway device")
-				gd, err = networking.GetGateway()
+				common.ThrowWarning("attempting to configure nat traversal")
+				natm, err = networking.ParseNAT(*natFlag)
+
+				if err != nil {
+					common.ThrowWarning("nat traversal unavailable: " + err.Error())
+				}
 			}
 
 			tsfRef := discovery.NodeID{}
@@ -68,18 +125,11 @@ func main() {
 
 				var ip string
 
-				var err error
-
-				if gd != nil {
-					ip, err = gd.ExternalIP()
-					if err != nil {
-						panic(err)
-					}
-				} else {
-					ip, err = networking.GetExtIPAddrNoUpNP()
+				if natm != nil {
+					ip, _ = networking.GetExtIPAddr(natm)
 				}
 
-				selfID := discovery.NodeID{} //Testing init of NodeID (self reference)
+				selfID := getSelfID()
 
 				db, err := discovery.NewNodeDatabase(selfID, ip) //Initializing net New NodeDatabase
 
@@ -87,14 +137,29 @@ func main() {
 					panic(err)
 				}
 
+				if ip == "" {
+					// No gateway reports external address; settle on address observed by peers
+					_, err = networking.DiscoverExternalAddr(db)
+
+					if err != nil {
+						common.ThrowWarning(err.Error())
+					}
+				}
+
 				db.WriteDbToMemory(common.GetCurrentDir())
 			}
-			if !*noUpNPFlag {
-				fmt.Println("configuring upnp devices")
-				networking.PrepareForConnection(gd, eDb)
+			if natm != nil {
+				fmt.Println("configuring port mappings via " + natm.String())
+				mapping, err = networking.PrepareForConnection(natm, eDb)
+
+				if err != nil {
+					common.ThrowWarning("port mapping failed: " + err.Error())
+				}
+
+				go removeMappingOnExit(mapping)
 			}
 		} else {
-			selfID := discovery.NodeID{} //Testing init of NodeID (self reference)
+			selfID := getSelfID()
 
 			db, err := discovery.NewNodeDatabase(selfID, "") //Initializing net New NodeDatabase
 
@@ -111,14 +176,11 @@ func main() {
 			panic(err)
 		}
 
+		db.RefreshBootstrap()
+
 		fmt.Println("\nbest node: " + db.FindNode())
 
 		if *relayFlag || *hostFlag || *fullChainFlag {
-			//Creating new account:
-
-			accountAddress := common.HexToAddress("4920616d204d697473756b6f204d6567756d69")
-			account := types.NewAccount(accountAddress)
-
 			//Creating witness data:
 
 			signature := types.HexToSignature("4920616d204d697473756b6f204d6567756d69")
@@ -126,9 +188,18 @@ func main() {
 
 			//Creating transaction, contract, chain
 
-			testchain := types.ReadChainFromMemory(common.GetCurrentDir())
+			testchain := types.ReadChainWithIdentifier(common.GetCurrentDir(), chainID)
 
-			test := types.NewTransaction(uint64(1), *account, types.HexToAddress("4920616d204d697473756b6f204d6567756d69"), common.IntToPointer(1000), []byte{0x11, 0x11, 0x11}, nil, nil)
+			if testchain == nil {
+				common.ThrowWarning("could not read local chain; fetch chain (--fetch) before relaying or hosting")
+				return
+			}
+
+			test, err := newTestTransaction(testchain.SpamPolicy.Difficulty)
+
+			if err != nil {
+				panic(err)
+			}
 
 			//Adding witness, transaction to chain
 
@@ -139,7 +210,12 @@ func main() {
 
 			testchain.WriteChainToMemory(common.GetCurrentDir())
 
-			testDesChain := types.ReadChainFromMemory(common.GetCurrentDir())
+			testDesChain := types.ReadChainWithIdentifier(common.GetCurrentDir(), chainID)
+
+			if testDesChain == nil {
+				common.ThrowWarning("could not read back local chain")
+				return
+			}
 
 			if *relayFlag {
 				fmt.Println("attempting to relay")
@@ -148,6 +224,21 @@ func main() {
 				fmt.Println("attempting to relay")
 				networking.RelayChain(testDesChain, db)
 			} else if *hostFlag {
+				if *loopFlag {
+					testDesChain.NodeDb = db
+					go networking.StartDiscovery(db, make(chan struct{}))
+				}
+
+				registry, err := types.ReadRegistryFromMemory(common.GetCurrentDir())
+
+				if err != nil {
+					panic(err)
+				}
+
+				networking.Chains = registry // Serve every chain current node participates in
+
+				networking.HostWitness = &witness // Witness relayed transactions into hosted chains
+
 				fmt.Println("attempting to host")
 				networking.HostChain(testDesChain, db, *loopFlag)
 			}
@@ -166,9 +257,18 @@ func main() {
 			}
 			os.Stdout.Write(b)
 		} else if *fetchFlag {
-			testDesChain := types.Chain{}
-			fmt.Println("attempting to fetch chain")
-			networking.FetchChainWithAdd(&testDesChain, db)
+			testDesChain := types.Chain{Identifier: chainID, NodeDb: db}
+
+			if localChain := types.ReadChainWithIdentifier(common.GetCurrentDir(), chainID); localChain != nil {
+				testDesChain = *localChain
+			}
+
+			fmt.Println("attempting to sync chain")
+			err := networking.SyncChain(&testDesChain, db)
+
+			if err != nil {
+				fmt.Println("error:", err)
+			}
 
 			// Dump fetched chain
 
@@ -178,6 +278,10 @@ func main() {
 			}
 			os.Stdout.Write(b)
 		}
+
+		networking.DisableConnections(mapping)
+	} else if *lightFlag {
+		runLightClient(chainID)
 	} else if *newChainFlag {
 		fmt.Println("creating new chain")
 
@@ -192,38 +296,124 @@ func main() {
 		eDb.WriteDbToMemory(common.GetCurrentDir())
 
 		testcontract := new(contracts.Contract)
-		testchain := types.Chain{ParentContract: testcontract, NodeDb: eDb, Version: 0}
+		testchain := types.Chain{ParentContract: testcontract, Identifier: chainID, NodeDb: eDb, Version: 0, SpamPolicy: spamPolicyFromFlags()}
+
+		registry, err := types.ReadRegistryFromMemory(common.GetCurrentDir())
+
+		if err != nil {
+			panic(err)
+		}
+
+		registry.Register(&testchain)
+		registry.WriteRegistryToMemory(common.GetCurrentDir())
+	} else if *newWalletFlag {
+		fmt.Println("creating new wallet")
+
+		w, mnemonic, err := wallet.New(common.GetCurrentDir()+wallet.WalletFile, os.Getenv(walletPasswordEnv), wallet.StandardScryptN, wallet.StandardScryptP)
+
+		if err != nil {
+			panic(err)
+		}
+
+		common.ThrowSuccess("wallet created; write down recovery mnemonic: " + mnemonic)
+		common.ThrowSuccess("first account: " + w.Accounts()[0].Address.String())
+	} else if *newTokenFlag != "" {
+		fmt.Println("creating new token")
+
+		params := strings.Split(*newTokenFlag, ":")
+
+		if len(params) != 3 {
+			panic(errors.New("token must be specified as name:symbol:supply"))
+		}
+
+		supply, err := types.ParseAmount(params[2])
+
+		if err != nil {
+			panic(err)
+		}
+
+		db, err := discovery.ReadDbFromMemory(common.GetCurrentDir())
+
+		if err != nil {
+			panic(err)
+		}
+
+		w, err := wallet.Open(common.GetCurrentDir() + wallet.WalletFile)
+
+		if err != nil {
+			panic(errors.New("token is issued by first wallet account; create wallet with --newwallet (" + err.Error() + ")"))
+		}
+
+		err = w.Unlock(os.Getenv(walletPasswordEnv))
+
+		if err != nil {
+			panic(err)
+		}
+
+		defer w.Lock()
+
+		issuer := types.NewAccount(w.Accounts()[0].Address)
+
+		tok, err := token.NewToken(params[0], params[1], supply, issuer.Address)
+
+		if err != nil {
+			panic(err)
+		}
+
+		tokenChain, err := tok.NewChain(*issuer, w.SignTx, db)
+
+		if err != nil {
+			panic(err)
+		}
+
+		tokenChain.SpamPolicy = spamPolicyFromFlags()
+
+		registry, err := types.ReadRegistryFromMemory(common.GetCurrentDir())
 
-		testchain.WriteChainToMemory(common.GetCurrentDir())
+		if err != nil {
+			panic(err)
+		}
+
+		registry.Register(tokenChain)
+		registry.WriteRegistryToMemory(common.GetCurrentDir())
+
+		common.ThrowSuccess("created token " + tok.Symbol + "; host with --chain " + tok.ID.String())
 	} else if *registerNode {
 		common.ThrowWarning("registering node")
 
-		gd, err := networking.GetGateway()
+		natm, err := networking.ParseNAT(*natFlag)
 
 		if err != nil {
 			panic(err)
 		}
 
-		ip, err := gd.ExternalIP()
+		db, err := discovery.ReadDbFromMemory(common.GetCurrentDir())
 
 		if err != nil {
 			panic(err)
 		}
 
-		db, err := discovery.ReadDbFromMemory(common.GetCurrentDir())
+		db.RefreshBootstrap()
+
+		ip, err := networking.GetExtIPAddr(natm)
+
+		if err != nil {
+			ip, err = networking.DiscoverExternalAddr(db) // No gateway reports external address; ask peers
+		}
 
 		if err != nil {
 			panic(err)
 		}
 
-		ch, err := networking.FetchChain(db)
+		ch, err := networking.FetchChainWithIdentifier(db, chainID)
 
 		if err != nil {
 			panic(err)
 		}
 
-		db.AddNode(ip, discovery.NodeID{})
-		*ch.NodeDb = *db
+		db.SelfRef = getSelfID() // Peers learn self from database relayed with chain
+		db.SelfAddr = ip
+		ch.NodeDb = db
 
 		networking.RelayChain(ch, db)
 	} else {
@@ -253,10 +443,180 @@ func main() {
 			*registerNode = true
 		} else if strings.Contains(text, "noupnp") {
 			*noUpNPFlag = true
+		} else if strings.Contains(text, "light") {
+			*lightFlag = true
 		}
 
-		if *relayFlag || *listenFlag || *hostFlag || *fetchFlag || *newChainFlag || *loopFlag || *fullChainFlag || *noUpNPFlag || *registerNode {
+		if *relayFlag || *listenFlag || *hostFlag || *fetchFlag || *newChainFlag || *loopFlag || *fullChainFlag || *noUpNPFlag || *registerNode || *lightFlag {
 			main()
 		}
 	}
 }
+
+// newTestTransaction - create test transaction carrying proof-of-work of specified difficulty, sent &
+// signed by first wallet account if wallet exists, otherwise by throwaway account
+func newTestTransaction(difficulty uint8) (*types.Transaction, error) {
+	recipient := common.HexToAddress("4920616d204d697473756b6f204d6567756d69")
+
+	if *toFlag != "" {
+		var err error
+
+		recipient, err = common.ParseAddress(*toFlag)
+
+		if err != nil {
+			return nil, errors.New("invalid recipient " + *toFlag + ": " + err.Error())
+		}
+	}
+
+	w, err := wallet.Open(common.GetCurrentDir() + wallet.WalletFile)
+
+	if err != nil {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+		if err != nil {
+			return nil, err
+		}
+
+		account := types.NewAccount(types.PubkeyToAddress(&key.PublicKey))
+
+		tx := types.NewTransaction(uint64(1), *account, recipient, types.NewAmount(1000), []byte{0x11, 0x11, 0x11}, nil, nil)
+
+		err = tx.SolveWork(difficulty)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return tx, tx.SignWith(key) // Unsigned transactions are not admitted
+	}
+
+	err = w.Unlock(os.Getenv(walletPasswordEnv))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer w.Lock()
+
+	tx, err := w.NewTransaction(w.Accounts()[0].Address, recipient, types.NewAmount(1000), []byte{0x11, 0x11, 0x11}, nil, nil)
+
+	if err != nil || difficulty == 0 {
+		return tx, err
+	}
+
+	err = tx.SolveWork(difficulty)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, w.SignTx(tx) // Work nonce is covered by signature
+}
+
+// spamPolicyFromFlags - spam policy of chain created by current invocation
+func spamPolicyFromFlags() types.SpamPolicy {
+	if *difficultyFlag > types.MaxWorkDifficulty {
+		panic(errors.New("difficulty may not exceed " + strconv.Itoa(types.MaxWorkDifficulty)))
+	}
+
+	return types.SpamPolicy{Difficulty: uint8(*difficultyFlag), RateLimit: *rateLimitFlag, RateWindow: *rateWindowFlag}
+}
+
+// runLightClient - sync checkpoint headers of chain with specified identifier, then verify balance of
+// --account & inclusion of --tx against them
+func runLightClient(chainID common.Identifier) {
+	db, err := discovery.ReadDbFromMemory(common.GetCurrentDir())
+
+	if err != nil {
+		db, err = discovery.NewNodeDatabase(getSelfID(), "")
+
+		if err != nil {
+			panic(err)
+		}
+
+		db.WriteDbToMemory(common.GetCurrentDir())
+	}
+
+	db.RefreshBootstrap()
+
+	client := light.NewClient(common.GetCurrentDir(), chainID, db)
+
+	fmt.Println("attempting to sync checkpoint headers")
+	err = client.SyncHeaders()
+
+	if err != nil {
+		common.ThrowWarning("header sync failed: " + err.Error())
+	}
+
+	latest := client.Latest()
+
+	if latest == nil {
+		common.ThrowWarning("no verified checkpoints")
+		return
+	}
+
+	common.ThrowSuccess("latest checkpoint: " + strconv.Itoa(latest.Number) + " (versions up to " + strconv.Itoa(latest.ToVersion) + ")")
+
+	if *accountFlag != "" {
+		addr, err := common.ParseAddress(*accountFlag)
+
+		if err != nil {
+			panic(err)
+		}
+
+		account, err := client.Account(addr)
+
+		if err != nil {
+			panic(err)
+		}
+
+		balance, err := account.Balance()
+
+		if err != nil {
+			panic(err)
+		}
+
+		common.ThrowSuccess("balance of " + addr.String() + ": " + balance.String() + " (as of checkpoint " + strconv.Itoa(account.Checked) + ")")
+		common.ThrowWarning("balance not proven complete; serving node may withhold transactions of account")
+	}
+
+	if *txFlag != "" {
+		hash, err := common.ParseHash(*txFlag)
+
+		if err != nil {
+			panic(err)
+		}
+
+		tx, err := client.Transaction(hash)
+
+		if err != nil {
+			panic(err)
+		}
+
+		common.ThrowSuccess("verified inclusion of transaction " + hash.String() + " at chain version " + strconv.Itoa(tx.ChainVersion))
+	}
+}
+
+// removeMappingOnExit - remove port mapping from gateway once process is interrupted
+func removeMappingOnExit(mapping *networking.PortMapping) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+
+	<-sig
+
+	common.ThrowWarning("removing port mappings")
+	networking.DisableConnections(mapping)
+
+	os.Exit(1)
+}
+
+// getSelfID - node ID of current node, derived from its identity key
+func getSelfID() discovery.NodeID {
+	key, err := networking.GetNodeKey()
+
+	if err != nil {
+		panic(err)
+	}
+
+	return discovery.PubkeyToNodeID(&key.PublicKey)
+}
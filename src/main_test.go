@@ -1,6 +1,9 @@
 package main
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -33,8 +36,13 @@ func TestRelayTx(t *testing.T) {
 
 	//Creating new account:
 
-	accountAddress := common.HexToAddress("4920616d204d697473756b6f204d6567756d69")
-	account := types.NewAccount(accountAddress)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account := types.NewAccount(types.PubkeyToAddress(&key.PublicKey))
 
 	//Creating witness data:
 
@@ -63,7 +71,11 @@ func TestRelayTx(t *testing.T) {
 		t.Errorf("Chain serialization failed: %s", sErr.Error())
 	}
 
-	test := types.NewTransaction(uint64(1), *account, types.HexToAddress("4920616d204d697473756b6f204d6567756d69"), common.IntToPointer(1000), []byte{0x11, 0x11, 0x11}, nil, nil)
+	test := types.NewTransaction(uint64(1), *account, common.HexToAddress("4920616d204d697473756b6f204d6567756d69"), types.NewAmount(1000), []byte{0x11, 0x11, 0x11}, nil, nil)
+
+	if err := test.SignWith(key); err != nil {
+		t.Fatal(err) // Unsigned transactions are not relayed
+	}
 
 	//Adding witness, transaction to chain
 
@@ -0,0 +1,87 @@
+package networking
+
+import (
+	"sync"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/core/mempool"
+	"github.com/mitsukomegumi/indo-go/src/core/token"
+	"github.com/mitsukomegumi/indo-go/src/core/types"
+)
+
+// Chains - chains hosted by current node; connections are dispatched to chain matching their chain ID
+var Chains = types.NewChainRegistry()
+
+var chainPools = struct {
+	sync.Mutex
+	pools map[string]*mempool.Mempool
+}{pools: make(map[string]*mempool.Mempool)}
+
+var chainLedgers = struct {
+	sync.Mutex
+	ledgers map[string]*token.Ledger
+}{ledgers: make(map[string]*token.Ledger)}
+
+// MempoolFor - mempool holding transactions pending for chain with specified identifier; the
+// default chain uses Mempool
+func MempoolFor(id common.Identifier) *mempool.Mempool {
+	if len(id) == 0 {
+		return Mempool
+	}
+
+	chainPools.Lock()
+	defer chainPools.Unlock()
+
+	pool, found := chainPools.pools[id.String()]
+
+	if !found {
+		pool = mempool.NewMempool(mempool.DefaultLimit, nil)
+		chainPools.pools[id.String()] = pool
+	}
+
+	return pool
+}
+
+// checkToken - check token operation carried by transaction against current balances of token chain;
+// ledger of each chain is kept between checks & only updated with transactions added since
+func checkToken(Ch *types.Chain, Tx *types.Transaction) error {
+	chainLedgers.Lock()
+	defer chainLedgers.Unlock()
+
+	key := Ch.Identifier.String()
+	ledger, found := chainLedgers.ledgers[key]
+
+	if !found || ledger.Update(Ch) != nil {
+		var err error
+
+		ledger, err = token.LedgerFromChain(Ch)
+
+		if err != nil {
+			delete(chainLedgers.ledgers, key)
+			return err
+		}
+
+		chainLedgers.ledgers[key] = ledger
+	}
+
+	return ledger.Check(Tx)
+}
+
+// chainFor - chain connection is scoped to; connections for chain other than specified local
+// chain are dispatched through registry of hosted chains
+func chainFor(conn *Connection, Ch *types.Chain) (*types.Chain, error) {
+	if Ch != nil && conn.ChainID.Equal(Ch.Identifier) {
+		return Ch, nil
+	}
+
+	return Chains.Get(conn.ChainID)
+}
+
+// chainScoped - check if connections of specified type concern single chain, rather than node
+func chainScoped(connType ConnectionType) bool {
+	switch connType {
+	case "fullchain", "relay", "fetchchain", "syncrequest", "proofrequest", "headerrequest", "accountrequest", "snapshotrequest":
+		return true
+	}
+	return false
+}
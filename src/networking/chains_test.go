@@ -0,0 +1,17 @@
+package networking
+
+import (
+	"testing"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+)
+
+func TestMempoolFor(t *testing.T) {
+	if MempoolFor(nil) != Mempool || MempoolFor(common.Identifier{0x0f}) == Mempool {
+		t.Errorf("chains not given separate mempools")
+	}
+
+	if MempoolFor(common.Identifier{0x0f}) != MempoolFor(common.Identifier{0x0f}) {
+		t.Errorf("chain not given same mempool on every call")
+	}
+}
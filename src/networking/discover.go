@@ -0,0 +1,148 @@
+package networking
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+)
+
+// DiscoveryInterval - time between routing table refresh rounds
+var DiscoveryInterval = 5 * time.Minute
+
+// FindNodeRequest - request for nodes closest to target ID
+type FindNodeRequest struct {
+	Target discovery.NodeID `json:"target"`
+}
+
+// Neighbors - nodes closest to requested target known by responding node
+type Neighbors struct {
+	Nodes []discovery.TableEntry `json:"nodes"`
+}
+
+// DiscoverNodes - perform single discovery round, seeding routing table from bootstrap nodes if
+// empty, then looking up self & random target; returns number of nodes in routing table
+func DiscoverNodes(Db *discovery.NodeDatabase) int {
+	table := Db.Table()
+
+	if table.Len() == 0 {
+		for _, addr := range Db.BootstrapNodeAddrs {
+			_, err := findNode(Db, discovery.TableEntry{Addr: addr}, Db.SelfRef)
+
+			if err != nil {
+				common.ThrowWarning("bootstrap node " + addr + " unreachable: " + err.Error())
+			}
+		}
+	}
+
+	targets := append([]discovery.NodeID{Db.SelfRef, discovery.RandomNodeID()}, table.RefreshTargets(DiscoveryInterval)...)
+
+	for _, target := range targets {
+		target := target
+
+		table.Lookup(target, func(entry discovery.TableEntry) ([]discovery.TableEntry, error) {
+			return findNode(Db, entry, target)
+		})
+	}
+
+	Db.ProbePeers(DiscoveryInterval) // Check liveness of peers not seen during lookups
+
+	common.ThrowSuccess("discovery round finished; " + strconv.Itoa(table.Len()) + " nodes known")
+
+	return table.Len()
+}
+
+// StartDiscovery - refresh routing table every discovery interval until quit is closed
+func StartDiscovery(Db *discovery.NodeDatabase, quit chan struct{}) {
+	DiscoverNodes(Db)
+
+	ticker := time.NewTicker(DiscoveryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			DiscoverNodes(Db)
+		case <-quit:
+			return
+		}
+	}
+}
+
+// findNode - ask node for nodes closest to target; responding node is added to database once it
+// has proven its ID
+func findNode(Db *discovery.NodeDatabase, entry discovery.TableEntry, target discovery.NodeID) ([]discovery.TableEntry, error) {
+	reqBytes, err := json.Marshal(FindNodeRequest{Target: target})
+
+	if err != nil {
+		return nil, err
+	}
+
+	conn := newConnection(Db.SelfAddr, entry.Addr, "findnode", reqBytes)
+
+	resp, err := conn.request(entry.ID, Db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Type != "neighbors" {
+		return nil, errors.New("unexpected response to node lookup: " + string(resp.Type))
+	}
+
+	neighbors := Neighbors{}
+	err = json.NewDecoder(bytes.NewReader(resp.Data)).Decode(&neighbors)
+
+	if err != nil {
+		Db.Misbehave(entry.Addr, resp.PeerID, discovery.ViolationUndecodable)
+		return nil, err
+	}
+
+	Db.AddVerifiedNode(entry.Addr, resp.PeerID)
+
+	var accepted []discovery.TableEntry
+
+	for _, neighbor := range neighbors.Nodes {
+		if len(accepted) == discovery.BucketSize {
+			break
+		}
+
+		if discovery.Addressing.Check(neighbor.Addr) == nil { // Drop private & reserved addresses unless allowed
+			accepted = append(accepted, neighbor)
+		}
+	}
+
+	return accepted, nil
+}
+
+// handleFindNode - respond to node lookup with closest known nodes; requesting node is added to
+// database, as it has proven its ID during handshake
+func handleFindNode(conn *Connection, Db *discovery.NodeDatabase, connec net.Conn) error {
+	if Db == nil {
+		return errors.New("no node database to serve lookup from")
+	}
+
+	req := FindNodeRequest{}
+	err := json.NewDecoder(bytes.NewReader(conn.Data)).Decode(&req)
+
+	if err != nil {
+		return err
+	}
+
+	Db.AddVerifiedNode(remoteHost(connec), conn.PeerID)
+
+	neighbors := Neighbors{Nodes: Db.Table().Closest(req.Target, discovery.BucketSize)}
+
+	neighborBytes, err := json.Marshal(neighbors)
+
+	if err != nil {
+		return err
+	}
+
+	return respond(connec, newConnection(Db.SelfAddr, conn.InitNodeAddr, "neighbors", neighborBytes))
+}
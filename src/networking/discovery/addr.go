@@ -0,0 +1,141 @@
+package discovery
+
+import (
+	"errors"
+	"net"
+	"strings"
+)
+
+var (
+	// ErrInvalidAddress - error returned when node address is not IP address
+	ErrInvalidAddress = errors.New("node address is not valid ip address")
+
+	// ErrPrivateAddress - error returned when node address lies in private range & private addresses are not allowed
+	ErrPrivateAddress = errors.New("node address is private")
+
+	// ErrReservedAddress - error returned when node address lies in range never reachable as node
+	ErrReservedAddress = errors.New("node address is reserved")
+)
+
+// AddressPolicy - policy determining which node addresses are accepted into node database
+type AddressPolicy struct {
+	AllowPrivate bool // Accept private, loopback & link-local addresses (LAN & test networks)
+}
+
+// Addressing - address policy applied to added & discovered nodes
+var Addressing = AddressPolicy{AllowPrivate: false}
+
+// privateRanges - ranges reachable only within local network or host
+var privateRanges = parseCIDRs(
+	"10.0.0.0/8",     // RFC 1918
+	"172.16.0.0/12",  // RFC 1918
+	"192.168.0.0/16", // RFC 1918
+	"100.64.0.0/10",  // Carrier-grade NAT
+	"127.0.0.0/8",    // Loopback
+	"169.254.0.0/16", // Link-local
+	"::1/128",        // Loopback
+	"fe80::/10",      // Link-local
+	"fc00::/7",       // Unique local
+)
+
+// reservedRanges - ranges never used by reachable nodes
+var reservedRanges = parseCIDRs(
+	"0.0.0.0/8",       // Unspecified
+	"192.0.0.0/24",    // IETF protocol assignments
+	"192.0.2.0/24",    // Documentation
+	"198.18.0.0/15",   // Benchmarking
+	"198.51.100.0/24", // Documentation
+	"203.0.113.0/24",  // Documentation
+	"224.0.0.0/4",     // Multicast
+	"240.0.0.0/4",     // Reserved & broadcast
+	"::/128",          // Unspecified
+	"2001:db8::/32",   // Documentation
+	"ff00::/8",        // Multicast
+)
+
+// ParseAddr - parse node address (IPv4 or IPv6, optionally bracketed or with port) into IP
+func ParseAddr(addr string) (net.IP, error) {
+	host := addr
+
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+
+	if zone := strings.LastIndex(host, "%"); zone >= 0 {
+		host = host[:zone] // Drop IPv6 zone
+	}
+
+	ip := net.ParseIP(host)
+
+	if ip == nil {
+		return nil, ErrInvalidAddress
+	}
+
+	return ip, nil
+}
+
+// IsPrivate - check if IP lies in range reachable only within local network or host
+func IsPrivate(ip net.IP) bool {
+	return inRanges(ip, privateRanges)
+}
+
+// IsReserved - check if IP lies in range never used by reachable nodes
+func IsReserved(ip net.IP) bool {
+	return inRanges(ip, reservedRanges)
+}
+
+// Check - check that address is acceptable as node address under policy
+func (policy AddressPolicy) Check(addr string) error {
+	ip, err := ParseAddr(addr)
+
+	if err != nil {
+		return err
+	}
+
+	if IsReserved(ip) {
+		return ErrReservedAddress
+	}
+
+	if IsPrivate(ip) && !policy.AllowPrivate {
+		return ErrPrivateAddress
+	}
+
+	return nil
+}
+
+// HostPort - join node address & port, bracketing IPv6 addresses
+func HostPort(addr string, port string) string {
+	return net.JoinHostPort(strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]"), port)
+}
+
+func inRanges(ip net.IP, ranges []*net.IPNet) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	for _, cidr := range ranges {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	var ranges []*net.IPNet
+
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+
+		if err != nil {
+			panic(err)
+		}
+
+		ranges = append(ranges, ipNet)
+	}
+
+	return ranges
+}
@@ -0,0 +1,58 @@
+package discovery
+
+import "testing"
+
+func TestAddressPolicy(t *testing.T) {
+	strict := AddressPolicy{}
+	lan := AddressPolicy{AllowPrivate: true}
+
+	tests := []struct {
+		addr   string
+		policy AddressPolicy
+		err    error
+	}{
+		{"8.192.1.1", strict, nil},
+		{"1.1.1.1", strict, nil},
+		{"2606:4700::1111", strict, nil},
+		{"[2606:4700::1111]:3000", strict, nil},
+		{"10.1.2.3", strict, ErrPrivateAddress},
+		{"172.20.0.1", strict, ErrPrivateAddress},
+		{"192.168.1.1", strict, ErrPrivateAddress},
+		{"127.0.0.1", strict, ErrPrivateAddress},
+		{"169.254.1.1", strict, ErrPrivateAddress},
+		{"fd00::1", strict, ErrPrivateAddress},
+		{"fe80::1%eth0", strict, ErrPrivateAddress},
+		{"::1", strict, ErrPrivateAddress},
+		{"10.1.2.3", lan, nil},
+		{"fd00::1", lan, nil},
+		{"::1", lan, nil},
+		{"0.0.0.0", lan, ErrReservedAddress},
+		{"224.0.0.1", lan, ErrReservedAddress},
+		{"255.255.255.255", lan, ErrReservedAddress},
+		{"2001:db8::1", lan, ErrReservedAddress},
+		{"::", lan, ErrReservedAddress},
+		{"not-an-ip", strict, ErrInvalidAddress},
+	}
+
+	for _, test := range tests {
+		if err := test.policy.Check(test.addr); err != test.err {
+			t.Errorf("%s (private allowed: %v): expected %v, got %v", test.addr, test.policy.AllowPrivate, test.err, err)
+		}
+	}
+}
+
+func TestHostPort(t *testing.T) {
+	tests := []struct {
+		addr     string
+		hostPort string
+	}{
+		{"1.1.1.1", "1.1.1.1:3000"},
+		{"2606:4700::1111", "[2606:4700::1111]:3000"},
+	}
+
+	for _, test := range tests {
+		if hostPort := HostPort(test.addr, DefaultPort); hostPort != test.hostPort {
+			t.Errorf("%s: expected %s, got %s", test.addr, test.hostPort, hostPort)
+		}
+	}
+}
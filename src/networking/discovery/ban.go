@@ -0,0 +1,282 @@
+package discovery
+
+import (
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+)
+
+// Violation - protocol violation committed by peer, with misbehavior score penalty
+type Violation struct {
+	Reason  string
+	Penalty int
+}
+
+var (
+	// ViolationUndecodable - peer sent data that could not be decoded
+	ViolationUndecodable = Violation{Reason: "undecodable data", Penalty: 20}
+
+	// ViolationOversized - peer sent message exceeding maximum message size
+	ViolationOversized = Violation{Reason: "oversized message", Penalty: 50}
+
+	// ViolationInvalidSignature - peer failed handshake or frame authentication
+	ViolationInvalidSignature = Violation{Reason: "invalid signature", Penalty: 50}
+
+	// ViolationInvalidChain - peer sent chain that could not be decoded or validated
+	ViolationInvalidChain = Violation{Reason: "invalid chain", Penalty: 50}
+
+	// ViolationInvalidTransaction - peer sent transaction that can never be valid
+	ViolationInvalidTransaction = Violation{Reason: "invalid transaction", Penalty: 20}
+)
+
+// BanPolicy - rules determining when misbehaving peers are banned
+type BanPolicy struct {
+	Threshold      int           // Misbehavior score at which peer is banned
+	Duration       time.Duration // Length of temporary ban
+	PermanentAfter int           // Number of temporary bans after which peer is banned permanently
+	ScoreExpiry    time.Duration // Misbehavior score is reset once no violation occurs for this long
+}
+
+// Banning - policy used to ban misbehaving peers
+var Banning = BanPolicy{Threshold: 100, Duration: 24 * time.Hour, PermanentAfter: 3, ScoreExpiry: time.Hour}
+
+// Misbehavior - misbehavior record of single peer address
+type Misbehavior struct {
+	Addr string   `json:"addr"`
+	IDs  []NodeID `json:"ids"` // Node IDs proven from address
+
+	Score         int       `json:"score"`
+	LastViolation time.Time `json:"lastviolation"`
+	Reason        string    `json:"reason"`
+
+	Bans        int       `json:"bans"` // Number of times peer has been banned
+	BannedUntil time.Time `json:"banneduntil"`
+	Permanent   bool      `json:"permanent"`
+}
+
+// Banned - check if record is banned at specified time
+func (record *Misbehavior) Banned(now time.Time) bool {
+	return record.Permanent || now.Before(record.BannedUntil)
+}
+
+// hasID - check if node ID has been proven from misbehaving address
+func (record *Misbehavior) hasID(id NodeID) bool {
+	for _, existing := range record.IDs {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (record *Misbehavior) copy() *Misbehavior {
+	cp := *record
+	cp.IDs = append([]NodeID(nil), record.IDs...)
+	return &cp
+}
+
+// Misbehave - record protocol violation committed by peer at address (& with ID, if proven),
+// banning peer once its score reaches ban threshold; returns true if peer is banned
+func (db *NodeDatabase) Misbehave(addr string, id NodeID, violation Violation) bool {
+	if db == nil {
+		return false
+	}
+
+	db.mu.Lock()
+
+	record := db.misbehavior(addr, id)
+
+	if record == nil {
+		db.mu.Unlock()
+		return false
+	}
+
+	now := time.Now().UTC()
+
+	if now.Sub(record.LastViolation) > Banning.ScoreExpiry {
+		record.Score = 0
+	}
+
+	record.Score += violation.Penalty
+	record.LastViolation = now
+	record.Reason = violation.Reason
+
+	banned := record.Banned(now)
+
+	if !banned && record.Score >= Banning.Threshold {
+		db.ban(record, Banning.Duration, now)
+		banned = true
+	}
+
+	ids := append([]NodeID(nil), record.IDs...)
+
+	db.mu.Unlock()
+
+	common.ThrowWarning("peer " + record.Addr + " misbehaved: " + violation.Reason)
+
+	if banned {
+		for _, bannedID := range ids {
+			db.RemovePeer(bannedID)
+		}
+	}
+
+	return banned
+}
+
+// Ban - ban peer at address (& with ID, if known) for specified duration; non-positive durations ban permanently
+func (db *NodeDatabase) Ban(addr string, id NodeID, duration time.Duration, reason string) {
+	db.mu.Lock()
+
+	record := db.misbehavior(addr, id)
+
+	if record == nil {
+		db.mu.Unlock()
+		return
+	}
+
+	record.Reason = reason
+
+	if duration <= 0 {
+		record.Permanent = true
+		record.Bans++
+	} else {
+		db.ban(record, duration, time.Now().UTC())
+	}
+
+	ids := append([]NodeID(nil), record.IDs...)
+
+	db.mu.Unlock()
+
+	for _, bannedID := range ids {
+		db.RemovePeer(bannedID)
+	}
+}
+
+// Unban - lift ban of peer at address & reset its misbehavior score, returning false if address has no record
+func (db *NodeDatabase) Unban(addr string) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	key := banKey(addr)
+
+	if _, found := db.Misbehavior[key]; !found {
+		return false
+	}
+
+	delete(db.Misbehavior, key)
+
+	return true
+}
+
+// IsBanned - check if peer at address, or with ID, is currently banned
+func (db *NodeDatabase) IsBanned(addr string, id NodeID) bool {
+	if db == nil {
+		return false
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.isBanned(addr, id)
+}
+
+// Bans - return copies of records of all currently banned peers
+func (db *NodeDatabase) Bans() []*Misbehavior {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var bans []*Misbehavior
+
+	now := time.Now()
+
+	for _, record := range db.Misbehavior {
+		if record.Banned(now) {
+			bans = append(bans, record.copy())
+		}
+	}
+
+	return bans
+}
+
+// isBanned - check ban without locking; caller must hold lock
+func (db *NodeDatabase) isBanned(addr string, id NodeID) bool {
+	now := time.Now()
+
+	if record, found := db.Misbehavior[banKey(addr)]; found && addr != "" && record.Banned(now) {
+		return true
+	}
+
+	if id == (NodeID{}) {
+		return false
+	}
+
+	for _, record := range db.Misbehavior {
+		if record.hasID(id) && record.Banned(now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// misbehavior - get or create misbehavior record of address, recording ID; caller must hold lock
+func (db *NodeDatabase) misbehavior(addr string, id NodeID) *Misbehavior {
+	if addr == "" {
+		if id == (NodeID{}) {
+			return nil
+		}
+
+		for _, record := range db.Misbehavior {
+			if record.hasID(id) {
+				return record
+			}
+		}
+
+		if peer, found := db.Peers[id]; found {
+			addr = peer.Addr()
+		}
+
+		if addr == "" {
+			addr = id.String() // No address known; track by ID alone
+		}
+	}
+
+	if db.Misbehavior == nil {
+		db.Misbehavior = make(map[string]*Misbehavior)
+	}
+
+	key := banKey(addr)
+	record, found := db.Misbehavior[key]
+
+	if !found {
+		record = &Misbehavior{Addr: key}
+		db.Misbehavior[key] = record
+	}
+
+	if id != (NodeID{}) && !record.hasID(id) {
+		record.IDs = append(record.IDs, id)
+	}
+
+	return record
+}
+
+// ban - ban record for specified duration, banning permanently once ban limit is reached; caller must hold lock
+func (db *NodeDatabase) ban(record *Misbehavior, duration time.Duration, now time.Time) {
+	record.Bans++
+	record.Score = 0
+	record.BannedUntil = now.Add(duration)
+
+	if Banning.PermanentAfter > 0 && record.Bans >= Banning.PermanentAfter {
+		record.Permanent = true
+	}
+
+	common.ThrowWarning("banned peer " + record.Addr + ": " + record.Reason)
+}
+
+// banKey - normalize address, so that equal IPs written differently share one record
+func banKey(addr string) string {
+	if ip, err := ParseAddr(addr); err == nil {
+		return ip.String()
+	}
+	return addr
+}
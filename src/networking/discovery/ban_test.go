@@ -0,0 +1,87 @@
+package discovery
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBanList(t *testing.T) {
+	db := &NodeDatabase{SelfRef: RandomNodeID()}
+
+	offender, other := RandomNodeID(), RandomNodeID()
+
+	db.AddPeer(&Peer{ID: offender, Addresses: []string{"1.1.1.1"}})
+	db.AddPeer(&Peer{ID: other, Addresses: []string{"2.2.2.2"}})
+
+	for x := 0; x < 4; x++ {
+		if db.Misbehave("1.1.1.1", offender, ViolationUndecodable) {
+			t.Fatalf("peer banned below threshold")
+		}
+	}
+
+	if !db.Misbehave("1.1.1.1", offender, ViolationUndecodable) {
+		t.Fatalf("peer not banned at threshold")
+	}
+
+	tests := []struct {
+		name   string
+		addr   string
+		id     NodeID
+		banned bool
+	}{
+		{"offending address", "1.1.1.1", NodeID{}, true},
+		{"offending node id", "", offender, true},
+		{"other peer", "2.2.2.2", other, false},
+	}
+
+	for _, test := range tests {
+		if db.IsBanned(test.addr, test.id) != test.banned {
+			t.Errorf("%s: expected banned %v", test.name, test.banned)
+		}
+	}
+
+	db.AddPeer(&Peer{ID: offender, Addresses: []string{"3.3.3.3"}})
+
+	if _, found := db.GetPeer(offender); found {
+		t.Errorf("banned peer re-added")
+	}
+
+	if peers := db.FindNodes(2); len(peers) != 1 || peers[0] != "2.2.2.2" {
+		t.Errorf("banned peer selected: %v", peers)
+	}
+
+	db.Ban("4.4.4.4", NodeID{}, 0, "manual")
+
+	b, err := json.Marshal(db)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := &NodeDatabase{}
+	err = json.Unmarshal(b, decoded)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bans := decoded.Bans(); len(bans) != 2 || !decoded.IsBanned("4.4.4.4", NodeID{}) {
+		t.Errorf("ban list not persisted: %d bans", len(bans))
+	}
+
+	if !decoded.Unban("1.1.1.1") || decoded.IsBanned("", offender) {
+		t.Errorf("ban not lifted")
+	}
+}
+
+func TestSingleViolationNotBanned(t *testing.T) {
+	tests := []Violation{ViolationUndecodable, ViolationOversized, ViolationInvalidSignature, ViolationInvalidChain, ViolationInvalidTransaction}
+
+	for _, violation := range tests {
+		db := &NodeDatabase{SelfRef: RandomNodeID()}
+
+		if db.Misbehave("1.1.1.1", RandomNodeID(), violation) {
+			t.Errorf("%s: peer banned for single violation", violation.Reason)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+)
+
+const (
+	// MainNetwork - name of main network
+	MainNetwork = "main"
+
+	// TestNetwork - name of private test network
+	TestNetwork = "test"
+
+	// BootstrapConfigFile - name of bootstrap config file read from current directory
+	BootstrapConfigFile = "bootstrap.json"
+
+	// BootstrapEnv - environment variable holding comma-separated bootstrap node addresses
+	BootstrapEnv = "INDO_BOOTSTRAP"
+
+	// seedTimeout - maximum time spent resolving single DNS seed
+	seedTimeout = 5 * time.Second
+)
+
+// BootstrapConfig - sources of bootstrap nodes for single network
+type BootstrapConfig struct {
+	Nodes    []string `json:"nodes"`    // Static bootstrap node addresses
+	DNSSeeds []string `json:"dnsseeds"` // Hostnames resolving (A/AAAA) to bootstrap node addresses
+}
+
+// Resolver - resolves DNS seed hostnames into node addresses; satisfied by *net.Resolver
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// DefaultBootstrap - built-in bootstrap configuration of each network; no public seeds are operated yet, so
+// nodes must be configured via BootstrapConfigFile, BootstrapEnv or the --bootstrap flag
+var DefaultBootstrap = map[string]BootstrapConfig{
+	MainNetwork: {},
+	TestNetwork: {},
+}
+
+// Bootstrap - bootstrap configuration used by node databases; set from LoadBootstrapConfig on startup
+var Bootstrap = DefaultBootstrap[MainNetwork]
+
+// SeedResolver - resolver used to look up DNS seeds
+var SeedResolver Resolver = net.DefaultResolver
+
+// ReadBootstrapConfig - read bootstrap configuration of each network from JSON file at specified path
+func ReadBootstrapConfig(path string) (map[string]BootstrapConfig, error) {
+	raw, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	configs := make(map[string]BootstrapConfig)
+	err = json.Unmarshal(raw, &configs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+// LoadBootstrapConfig - build bootstrap configuration of specified network from (in increasing
+// precedence) built-in defaults, config file at path, environment variable & comma-separated flag value
+func LoadBootstrapConfig(network string, path string, flagNodes string) BootstrapConfig {
+	config := DefaultBootstrap[network]
+
+	if configs, err := ReadBootstrapConfig(path); err == nil {
+		if fileConfig, found := configs[network]; found {
+			config = fileConfig
+		}
+	} else if !os.IsNotExist(err) {
+		common.ThrowWarning("invalid bootstrap config: " + err.Error())
+	}
+
+	if nodes := splitAddrs(os.Getenv(BootstrapEnv)); len(nodes) > 0 {
+		config.Nodes = nodes
+	}
+
+	if nodes := splitAddrs(flagNodes); len(nodes) > 0 {
+		config.Nodes = nodes
+	}
+
+	return config
+}
+
+// Resolve - return static bootstrap nodes followed by addresses of each DNS seed, without duplicates
+func (config BootstrapConfig) Resolve(resolver Resolver) []string {
+	var addrs []string
+
+	seen := make(map[string]bool)
+
+	add := func(addr string) {
+		if addr != "" && !seen[addr] {
+			seen[addr] = true
+			addrs = append(addrs, addr)
+		}
+	}
+
+	for _, addr := range config.Nodes {
+		add(addr)
+	}
+
+	for _, seed := range config.DNSSeeds {
+		ctx, cancel := context.WithTimeout(context.Background(), seedTimeout)
+		resolved, err := resolver.LookupHost(ctx, seed)
+		cancel()
+
+		if err != nil {
+			common.ThrowWarning("dns seed " + seed + " unresolvable: " + err.Error())
+			continue
+		}
+
+		for _, addr := range resolved {
+			add(addr)
+		}
+	}
+
+	return addrs
+}
+
+// RefreshBootstrap - replace bootstrap node addresses of database with those of current bootstrap configuration
+func (db *NodeDatabase) RefreshBootstrap() {
+	addrs := Bootstrap.Resolve(SeedResolver)
+
+	db.mu.Lock()
+	db.BootstrapNodeAddrs = addrs
+	db.mu.Unlock()
+}
+
+func splitAddrs(list string) []string {
+	var addrs []string
+
+	for _, addr := range strings.Split(list, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+
+	return addrs
+}
@@ -0,0 +1,85 @@
+package discovery
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type seedResolverStub map[string][]string
+
+func (stub seedResolverStub) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if addrs, found := stub[host]; found {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func TestBootstrapConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bootstrap")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, BootstrapConfigFile)
+	err = ioutil.WriteFile(path, []byte(`{"test": {"nodes": ["1.1.1.1"], "dnsseeds": ["seed.test", "missing.test"]}}`), 0644)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Unsetenv(BootstrapEnv)
+
+	tests := []struct {
+		name  string
+		env   string
+		flag  string
+		nodes []string
+	}{
+		{"config file", "", "", []string{"1.1.1.1"}},
+		{"environment", "3.3.3.3, 4.4.4.4", "", []string{"3.3.3.3", "4.4.4.4"}},
+		{"flag", "3.3.3.3", "5.5.5.5", []string{"5.5.5.5"}},
+	}
+
+	for _, test := range tests {
+		os.Setenv(BootstrapEnv, test.env)
+
+		config := LoadBootstrapConfig(TestNetwork, path, test.flag)
+
+		if len(config.Nodes) != len(test.nodes) {
+			t.Errorf("%s: unexpected bootstrap nodes: %v", test.name, config.Nodes)
+			continue
+		}
+
+		for x := range test.nodes {
+			if config.Nodes[x] != test.nodes[x] {
+				t.Errorf("%s: unexpected bootstrap nodes: %v", test.name, config.Nodes)
+			}
+		}
+	}
+
+	os.Setenv(BootstrapEnv, "")
+
+	config := LoadBootstrapConfig(TestNetwork, path, "")
+	resolver := seedResolverStub{"seed.test": {"2.2.2.2", "1.1.1.1", "2001:db8::1"}}
+
+	if addrs := config.Resolve(resolver); len(addrs) != 3 || addrs[0] != "1.1.1.1" || addrs[2] != "2001:db8::1" {
+		t.Errorf("unexpected bootstrap addresses: %v", addrs)
+	}
+}
+
+func TestDefaultBootstrapRoutable(t *testing.T) {
+	for network, config := range DefaultBootstrap {
+		for _, addr := range config.Nodes {
+			if err := Addressing.Check(addr); err != nil {
+				t.Errorf("%s: default bootstrap node %s not routable: %s", network, addr, err.Error())
+			}
+		}
+	}
+}
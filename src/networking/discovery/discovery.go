@@ -1,69 +1,92 @@
 package discovery
 
 import (
+	"encoding/json"
 	"fmt"
-	"net"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mitsukomegumi/indo-go/src/common"
-	"github.com/mitsukomegumi/indo-go/src/networking/fastping"
 )
 
-const (
-	bootStrapNode1Addr = "10.144.4.68"
-)
-
-// NodeDatabase - struct holding arrays of IP addresses, node IDs, etc...
+// NodeDatabase - directory of known peers keyed by node ID; safe for concurrent use
 type NodeDatabase struct {
-	NodeRefDB          []NodeID
-	NodePingTimeDB     []time.Time
-	NodeAddress        []string
+	Peers              map[NodeID]*Peer
 	SelfRef            NodeID
 	SelfAddr           string
 	BootstrapNodeAddrs []string
+
+	Misbehavior map[string]*Misbehavior // Misbehavior records & bans, keyed by peer address
+
+	mu    sync.RWMutex
+	table *Table // Routing table of discovered nodes; rebuilt from database when read
 }
 
 // NodeID - byte array identifying individual node
 type NodeID [64]byte
 
+// LegacyNodeDatabase - node database format preceding peer records (parallel slices of IDs,
+// ping times & addresses); only used to migrate previously serialized databases
+type LegacyNodeDatabase struct {
+	NodeRefDB          [][64]byte
+	NodePingTimeDB     []time.Time
+	NodeAddress        []string
+	SelfRef            [64]byte
+	SelfAddr           string
+	BootstrapNodeAddrs []string
+}
+
+// IsLegacy - check if decoded database holds data only present in legacy format
+func (legacy *LegacyNodeDatabase) IsLegacy() bool {
+	return len(legacy.NodeRefDB) > 0 || len(legacy.NodeAddress) > 0 || legacy.SelfRef != [64]byte{}
+}
+
+// Upgrade - migrate legacy node database into peer records
+func (legacy *LegacyNodeDatabase) Upgrade() *NodeDatabase {
+	db := &NodeDatabase{Peers: make(map[NodeID]*Peer), SelfRef: legacy.SelfRef, SelfAddr: legacy.SelfAddr, BootstrapNodeAddrs: legacy.BootstrapNodeAddrs}
+
+	for x, id := range legacy.NodeRefDB {
+		if x >= len(legacy.NodeAddress) {
+			break
+		}
+
+		db.AddPeer(&Peer{ID: id, Addresses: []string{legacy.NodeAddress[x]}, Source: PeerSourceLegacy})
+
+		if x < len(legacy.NodePingTimeDB) {
+			seen := legacy.NodePingTimeDB[x] // Recorded locally by legacy database
+
+			db.UpdatePeer(id, func(peer *Peer) {
+				peer.FirstSeen = seen
+				peer.LastSeen = seen
+			})
+		}
+	}
+
+	return db
+}
+
 // FindNode - find best node to connect to, returns ip address as string
 func (db *NodeDatabase) FindNode() string {
 	if !reflect.ValueOf(db).IsNil() {
-		if len(db.NodeAddress) == 0 {
-			ReadDbFromMemory(common.GetCurrentDir())
+		if best := db.FindNodes(1); len(best) > 0 {
+			return best[0]
 		}
-		return db.getBestNode()
+		return db.getBootstrap()
 	}
 	common.ThrowWarning("nil db")
-	return "10.144.4.68"
-}
-
-func (db *NodeDatabase) getBestNode() string {
-	if len(db.NodeAddress) > 0 {
-		x := 0
-		bestMatchPingTime := db.NodePingTimeDB[0]
-		nodeIndex := 0
-		for x != len(db.NodeAddress)-1 {
-			if db.NodePingTimeDB[x].After(bestMatchPingTime) {
-				bestMatchPingTime = db.NodePingTimeDB[x]
-				nodeIndex = x
-			}
-			x++
-		}
-		return db.NodeAddress[nodeIndex]
+	if addrs := Bootstrap.Resolve(SeedResolver); len(addrs) > 0 {
+		return addrs[0]
 	}
-	return db.getBootstrap()
+	return ""
 }
 
 func (db *NodeDatabase) getBootstrap() string {
-	x := 0
-	for x != len(db.BootstrapNodeAddrs)-1 {
-		if TestIP(db.BootstrapNodeAddrs[x]) {
-			return db.BootstrapNodeAddrs[x]
+	for _, addr := range db.BootstrapNodeAddrs {
+		if TestIP(addr) {
+			return addr
 		}
-		x++
 	}
 	return ""
 }
@@ -80,30 +103,323 @@ func NewNodeDatabase(selfRef NodeID, selfAddr string) (*NodeDatabase, error) {
 
 	if readDb != nil {
 		fmt.Println("read existing node database from mem")
+		readDb.RefreshBootstrap() // Bootstrap configuration takes precedence over persisted list
 		return readDb, nil
 	}
-	var tempArr []string
-	tempArr = append(tempArr, bootStrapNode1Addr)
-	return &NodeDatabase{SelfRef: selfRef, SelfAddr: selfAddr, BootstrapNodeAddrs: tempArr}, nil
+	db := &NodeDatabase{Peers: make(map[NodeID]*Peer), SelfRef: selfRef, SelfAddr: selfAddr}
+	db.RefreshBootstrap()
+	db.Table()
+	return db, nil
 }
 
 // AddNode - add specified IP address & ID to node directory
 func (db *NodeDatabase) AddNode(ip string, id NodeID) {
-	if !strings.Contains(ip, "192.") {
-		if TestIP(ip) {
-			fmt.Println("adding node to database")
-			db.NodeAddress = append(db.NodeAddress, ip)
-			db.NodePingTimeDB = append(db.NodePingTimeDB, time.Now().UTC())
-			db.NodeRefDB = append(db.NodeRefDB, id)
+	if id == (NodeID{}) {
+		common.ThrowWarning("database error: node id required")
+		return
+	}
+
+	if err := Addressing.Check(ip); err != nil {
+		common.ThrowWarning("database error: " + err.Error())
+		return
+	}
+
+	rtt, err := CheckLiveness(ip, id)
+
+	if err != nil {
+		common.ThrowWarning("database error: node unreachable: " + err.Error())
+		return
+	}
+
+	fmt.Println("adding node to database")
+	db.AddPeer(&Peer{ID: id, Addresses: []string{ip}, Source: PeerSourceManual})
+	db.RecordContact(id, rtt)
+}
+
+// AddVerifiedNode - add node that has proven its ID over an authenticated connection to node directory
+// & routing table
+func (db *NodeDatabase) AddVerifiedNode(ip string, id NodeID) {
+	if ip == "" || id == db.SelfRef || Addressing.Check(ip) != nil {
+		return
+	}
+
+	db.AddPeer(&Peer{ID: id, Addresses: []string{ip}, Source: PeerSourceHandshake})
+
+	db.UpdatePeer(id, func(peer *Peer) {
+		peer.LastSeen = time.Now().UTC()
+	})
+}
+
+// AddPeer - add peer record to database, merging with existing record for same node ID; only ID, source &
+// addresses accepted by address policy are kept, as contact metrics (last seen, RTT, failures, reputation)
+// are only ever recorded locally
+func (db *NodeDatabase) AddPeer(peer *Peer) {
+	if peer == nil || peer.ID == (NodeID{}) || peer.ID == db.SelfRef {
+		return
+	}
+
+	db.mu.Lock()
+
+	var addrs []string
+
+	for _, addr := range peer.Addresses {
+		if Addressing.Check(addr) == nil && !db.isBanned(addr, peer.ID) {
+			addrs = append(addrs, addr)
+		}
+	}
+
+	if len(addrs) == 0 || db.isBanned("", peer.ID) {
+		db.mu.Unlock()
+		return
+	}
+
+	if db.Peers == nil {
+		db.Peers = make(map[NodeID]*Peer)
+	}
+
+	existing, found := db.Peers[peer.ID]
+
+	if !found {
+		existing = &Peer{ID: peer.ID, FirstSeen: time.Now().UTC(), Source: peer.Source}
+		db.Peers[peer.ID] = existing
+	}
+
+	for _, addr := range addrs {
+		existing.addAddress(addr)
+	}
+
+	addr := existing.Addr()
+
+	db.mu.Unlock()
+
+	db.Table().Add(TableEntry{ID: peer.ID, Addr: addr})
+}
+
+// UpdatePeer - apply specified update to peer record, returning false if peer is unknown
+func (db *NodeDatabase) UpdatePeer(id NodeID, update func(*Peer)) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	peer, found := db.Peers[id]
+
+	if !found {
+		return false
+	}
+
+	update(peer)
+
+	return true
+}
+
+// RemovePeer - remove peer from database & routing table, returning false if peer is unknown
+func (db *NodeDatabase) RemovePeer(id NodeID) bool {
+	db.mu.Lock()
+
+	_, found := db.Peers[id]
+	delete(db.Peers, id)
+
+	db.mu.Unlock()
+
+	if found {
+		db.Table().Remove(id)
+	}
+
+	return found
+}
+
+// GetPeer - return copy of peer record for specified node ID
+func (db *NodeDatabase) GetPeer(id NodeID) (*Peer, bool) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	peer, found := db.Peers[id]
+
+	if !found {
+		return nil, false
+	}
+
+	return peer.copy(), true
+}
+
+// GetSelfAddr - return external address of current node
+func (db *NodeDatabase) GetSelfAddr() string {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.SelfAddr
+}
+
+// SetSelfAddr - set external address of current node, returning false if address is unchanged
+func (db *NodeDatabase) SetSelfAddr(addr string) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.SelfAddr == addr {
+		return false
+	}
+
+	db.SelfAddr = addr
+
+	return true
+}
+
+// PeerID - return ID of known peer reachable at specified address; zero ID if no known peer uses address
+func (db *NodeDatabase) PeerID(addr string) NodeID {
+	if db == nil {
+		return NodeID{}
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for id, peer := range db.Peers {
+		for _, peerAddr := range peer.Addresses {
+			if peerAddr == addr {
+				return id
+			}
+		}
+	}
+
+	return NodeID{}
+}
+
+// ListPeers - return copies of all peer records in database
+func (db *NodeDatabase) ListPeers() []*Peer {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	peers := make([]*Peer, 0, len(db.Peers))
+
+	for _, peer := range db.Peers {
+		peers = append(peers, peer.copy())
+	}
+
+	return peers
+}
+
+// Len - number of peers in database
+func (db *NodeDatabase) Len() int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return len(db.Peers)
+}
+
+// Addresses - return most recently confirmed address of every peer in database
+func (db *NodeDatabase) Addresses() []string {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var addrs []string
+
+	for _, peer := range db.Peers {
+		if addr := peer.Addr(); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+
+	return addrs
+}
+
+// RecordContact - record successful contact with peer, with measured round trip time
+func (db *NodeDatabase) RecordContact(id NodeID, rtt time.Duration) {
+	db.UpdatePeer(id, func(peer *Peer) {
+		peer.LastSeen = time.Now().UTC()
+		peer.LastRTT = rtt
+		peer.Failures = 0
+		peer.Reputation++
+	})
+}
+
+// RecordFailure - record failed contact with peer
+func (db *NodeDatabase) RecordFailure(id NodeID) {
+	db.UpdatePeer(id, func(peer *Peer) {
+		peer.Failures++
+	})
+}
+
+// LastPing - Get last time node was seen; returns false if node is unknown
+func (db *NodeDatabase) LastPing(id NodeID) (time.Time, bool) {
+	peer, found := db.GetPeer(id)
+
+	if !found {
+		return time.Time{}, false
+	}
+
+	return peer.LastSeen, true
+}
+
+// Merge - add IDs & addresses of peers held in other database (e.g. database received alongside chain) to
+// database; remote contact metrics are discarded
+func (db *NodeDatabase) Merge(other *NodeDatabase) {
+	if other == nil || other == db {
+		return
+	}
+
+	for _, peer := range other.ListPeers() {
+		db.AddPeer(&Peer{ID: peer.ID, Addresses: peer.Addresses, Source: PeerSourceDiscovery}) // Remote metrics never imported
+	}
+
+	if other.SelfRef != (NodeID{}) && other.SelfAddr != "" {
+		db.AddPeer(&Peer{ID: other.SelfRef, Addresses: []string{other.SelfAddr}, Source: PeerSourceDiscovery})
+	}
+}
+
+// Table - routing table of nodes known to current node, keyed by XOR distance from self
+func (db *NodeDatabase) Table() *Table {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.table == nil || db.table.Self() != db.SelfRef {
+		db.table = NewTable(db.SelfRef)
+
+		for id, peer := range db.Peers {
+			db.table.Add(TableEntry{ID: id, Addr: peer.Addr(), LastSeen: peer.LastSeen})
 		}
-	} else {
-		common.ThrowWarning("database error: node cannot be internal")
 	}
+	return db.table
+}
+
+// MarshalJSON - encode database while holding read lock
+func (db *NodeDatabase) MarshalJSON() ([]byte, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return json.Marshal(nodeDatabaseJSON{Peers: db.Peers, SelfRef: db.SelfRef, SelfAddr: db.SelfAddr, BootstrapNodeAddrs: db.BootstrapNodeAddrs, Misbehavior: db.Misbehavior})
+}
+
+// UnmarshalJSON - decode database
+func (db *NodeDatabase) UnmarshalJSON(b []byte) error {
+	dec := nodeDatabaseJSON{}
+
+	err := json.Unmarshal(b, &dec)
+
+	if err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	db.Peers, db.SelfRef, db.SelfAddr, db.BootstrapNodeAddrs, db.Misbehavior = dec.Peers, dec.SelfRef, dec.SelfAddr, dec.BootstrapNodeAddrs, dec.Misbehavior
+	db.table = nil
+	db.mu.Unlock()
+
+	return nil
+}
+
+// nodeDatabaseJSON - serialized fields of node database
+type nodeDatabaseJSON struct {
+	Peers              map[NodeID]*Peer
+	SelfRef            NodeID
+	SelfAddr           string
+	BootstrapNodeAddrs []string
+	Misbehavior        map[string]*Misbehavior
 }
 
 // WriteDbToMemory - create serialized instance of specified NodeDatabase in specified path (string)
 func (db *NodeDatabase) WriteDbToMemory(path string) error {
+	db.mu.RLock()
 	err := common.WriteGob(path+"nodeDb.gob", db)
+	db.mu.RUnlock()
 
 	if err != nil {
 		fmt.Println(err)
@@ -115,60 +431,39 @@ func (db *NodeDatabase) WriteDbToMemory(path string) error {
 	return nil
 }
 
-// ReadDbFromMemory - read serialized object of specified node database from specified path
+// ReadDbFromMemory - read serialized object of specified node database from specified path;
+// databases in legacy format are migrated to peer records
 func ReadDbFromMemory(path string) (*NodeDatabase, error) {
 	tempDb := new(NodeDatabase)
 
 	err := common.ReadGob(path+"nodeDb.gob", tempDb)
-	if err != nil {
+	if err != nil && (strings.Contains(err.Error(), "no such file") || strings.Contains(err.Error(), "cannot find the file")) {
 		return nil, err
 	}
-	return tempDb, nil
-}
 
-// TestIP - ping specified IP address to test for validity
-func TestIP(ip string) bool {
-	p := fastping.NewPinger()
-	ipAddress, err := net.ResolveIPAddr("ip", ip)
-	p.AddIPAddr(ipAddress)
+	// Legacy fields are silently skipped when decoding into current format; check for them explicitly
+	if legacyDb := new(LegacyNodeDatabase); common.ReadGob(path+"nodeDb.gob", legacyDb) == nil && legacyDb.IsLegacy() && (err != nil || len(tempDb.Peers) == 0) {
+		common.ThrowWarning("migrating legacy node database")
 
-	returnVal := false
-
-	p.OnRecv = func(addr *net.IPAddr, rtt time.Duration) {
-		fmt.Printf("IP Addr: %s receive, RTT: %v\n", addr.String(), rtt)
-		fmt.Printf("IP %s tested successfully \n", addr.String())
-		returnVal = true
+		tempDb, err = legacyDb.Upgrade(), nil
 	}
-	p.OnIdle = func() {
-		if returnVal != true {
-			fmt.Printf("Timed out with IP %s \n", ipAddress)
-			returnVal = false
-		}
-	}
-	err = p.Run()
+
 	if err != nil {
-		if strings.Contains(err.Error(), "operation not permitted") {
-			fmt.Println("operation requires root priveleges")
-		} else {
-			fmt.Println(err)
-		}
-		returnVal = false
+		return nil, err
 	}
-	return returnVal
+	tempDb.Table()
+	return tempDb, nil
 }
 
-// LastPing - Get last ping time for node
-func (db *NodeDatabase) LastPing(id NodeID) time.Time {
-	nodeIndex := db.GetNodeIndex(id)
-	return db.NodePingTimeDB[nodeIndex]
-}
+// TestIP - check that node at specified IP address is live, using unprivileged liveness checks
+func TestIP(ip string) bool {
+	_, err := CheckLiveness(ip, NodeID{})
 
-// GetNodeIndex - fetch/retrieve node index from node reference
-func (db *NodeDatabase) GetNodeIndex(id NodeID) int {
-	for k, v := range db.NodeRefDB {
-		if id == v {
-			return k
-		}
+	if err != nil {
+		fmt.Printf("IP %s unreachable: %s\n", ip, err.Error())
+		return false
 	}
-	return 0
+
+	fmt.Printf("IP %s tested successfully \n", ip)
+	return true
 }
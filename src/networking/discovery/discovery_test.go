@@ -0,0 +1,117 @@
+package discovery
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNodeDatabasePeers(t *testing.T) {
+	db := &NodeDatabase{SelfRef: RandomNodeID()}
+	known := RandomNodeID()
+
+	tests := []struct {
+		name  string
+		peer  *Peer
+		len   int
+		addr  string
+		addrs int
+	}{
+		{"new peer", &Peer{ID: known, Addresses: []string{"1.1.1.1"}, Source: PeerSourceManual}, 1, "1.1.1.1", 1},
+		{"new address of known peer", &Peer{ID: known, Addresses: []string{"2.2.2.2"}}, 1, "2.2.2.2", 2},
+		{"self", &Peer{ID: db.SelfRef, Addresses: []string{"3.3.3.3"}}, 1, "2.2.2.2", 2},
+		{"no node id", &Peer{Addresses: []string{"4.4.4.4"}}, 1, "2.2.2.2", 2},
+		{"private address", &Peer{ID: known, Addresses: []string{"10.0.0.1"}}, 1, "2.2.2.2", 2},
+		{"private address of new peer", &Peer{ID: RandomNodeID(), Addresses: []string{"192.168.1.1"}}, 1, "2.2.2.2", 2},
+		{"inflated reputation", &Peer{ID: known, Addresses: []string{"2.2.2.2"}, Reputation: 1000, LastRTT: time.Nanosecond, Failures: -5}, 1, "2.2.2.2", 2},
+	}
+
+	for _, test := range tests {
+		db.AddPeer(test.peer)
+
+		peer, found := db.GetPeer(known)
+
+		if !found || db.Len() != test.len || peer.Addr() != test.addr || len(peer.Addresses) != test.addrs {
+			t.Errorf("%s: peer records not merged by node id", test.name)
+		}
+
+		if peer.Reputation != 0 || peer.LastRTT != 0 || peer.Failures != 0 {
+			t.Errorf("%s: peer metrics copied from added record", test.name)
+		}
+	}
+
+	db.RecordContact(known, 20*time.Millisecond)
+
+	if peer, _ := db.GetPeer(known); peer.LastRTT != 20*time.Millisecond || peer.Reputation != 1 {
+		t.Errorf("contact not recorded")
+	}
+
+	if _, found := db.LastPing(RandomNodeID()); found {
+		t.Errorf("last ping reported for unknown node")
+	}
+
+	if !db.RemovePeer(known) || db.Len() != 0 || db.Table().Len() != 0 {
+		t.Errorf("peer not removed")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	db := &NodeDatabase{SelfRef: RandomNodeID()}
+	banned := RandomNodeID()
+	db.Misbehave("5.5.5.5", banned, ViolationInvalidChain)
+	db.Misbehave("5.5.5.5", banned, ViolationInvalidChain)
+
+	inflated, private := RandomNodeID(), RandomNodeID()
+
+	remote := &NodeDatabase{SelfRef: RandomNodeID(), SelfAddr: "1.1.1.1", Peers: map[NodeID]*Peer{
+		inflated: {ID: inflated, Addresses: []string{"2.2.2.2"}, Reputation: 1000, LastRTT: time.Nanosecond, LastSeen: time.Now().Add(time.Hour), Source: PeerSourceManual},
+		private:  {ID: private, Addresses: []string{"10.0.0.1"}},
+		banned:   {ID: banned, Addresses: []string{"6.6.6.6"}},
+	}}
+
+	db.Merge(remote)
+
+	tests := []struct {
+		name  string
+		id    NodeID
+		known bool
+	}{
+		{"remote self", remote.SelfRef, true},
+		{"inflated reputation", inflated, true},
+		{"private address", private, false},
+		{"banned", banned, false},
+	}
+
+	for _, test := range tests {
+		peer, found := db.GetPeer(test.id)
+
+		if found != test.known {
+			t.Errorf("%s: expected known %v, got %v", test.name, test.known, found)
+		} else if found && (peer.Reputation != 0 || peer.LastRTT != 0 || !peer.LastSeen.IsZero() || peer.Source != PeerSourceDiscovery) {
+			t.Errorf("%s: remote peer metrics imported (%+v)", test.name, peer)
+		}
+	}
+}
+
+func TestPeerID(t *testing.T) {
+	db := &NodeDatabase{SelfRef: RandomNodeID()}
+	known := RandomNodeID()
+	db.AddPeer(&Peer{ID: known, Addresses: []string{"1.1.1.1", "2.2.2.2"}})
+
+	tests := []struct {
+		name string
+		db   *NodeDatabase
+		addr string
+		id   NodeID
+	}{
+		{"latest address", db, "2.2.2.2", known},
+		{"earlier address", db, "1.1.1.1", known},
+		{"unknown address", db, "3.3.3.3", NodeID{}},
+		{"no database", nil, "1.1.1.1", NodeID{}},
+	}
+
+	for _, test := range tests {
+		if id := test.db.PeerID(test.addr); id != test.id {
+			t.Errorf("%s: expected %s, got %s", test.name, test.id, id)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package discovery
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/networking/fastping"
+)
+
+const (
+	// DefaultPort - port nodes accept connections on
+	DefaultPort = "3000"
+
+	probeTimeout = 3 * time.Second
+)
+
+// LivenessCheck - check that node at address (with specified ID, if known) is live, returning measured round trip time
+type LivenessCheck func(addr string, id NodeID) (time.Duration, error)
+
+// Liveness - liveness checks, tried in order until one succeeds; protocol-level ping is
+// installed ahead of the TCP connect probe by the networking package
+var Liveness = []LivenessCheck{TCPProbe}
+
+// CheckLiveness - check that node at address is live, trying each liveness check in turn; later checks are
+// only tried if connecting failed, so node reached but failing check (e.g. proving different node ID) is
+// never reported live by weaker check
+func CheckLiveness(addr string, id NodeID) (time.Duration, error) {
+	err := errors.New("no liveness checks configured")
+
+	for _, check := range Liveness {
+		var rtt time.Duration
+
+		rtt, err = check(addr, id)
+
+		if err == nil {
+			return rtt, nil
+		}
+
+		if _, connFailed := err.(net.Error); !connFailed {
+			return 0, err
+		}
+	}
+
+	return 0, err
+}
+
+// TCPProbe - check that node accepts TCP connections on node port; does not verify node ID
+func TCPProbe(addr string, id NodeID) (time.Duration, error) {
+	start := time.Now()
+
+	conn, err := net.DialTimeout("tcp", HostPort(addr, DefaultPort), probeTimeout)
+
+	if err != nil {
+		return 0, err
+	}
+
+	rtt := time.Since(start)
+	conn.Close()
+
+	return rtt, nil
+}
+
+// ICMPProbe - ping address via ICMP; requires root privileges & may be blocked by hosts, so it is
+// not included in default liveness checks
+func ICMPProbe(addr string, id NodeID) (time.Duration, error) {
+	p := fastping.NewPinger()
+	ipAddress, err := net.ResolveIPAddr("ip", addr)
+
+	if err != nil {
+		return 0, err
+	}
+
+	p.AddIPAddr(ipAddress)
+
+	var measured time.Duration
+	received := false
+
+	p.OnRecv = func(addr *net.IPAddr, rtt time.Duration) {
+		measured = rtt
+		received = true
+	}
+
+	err = p.Run()
+
+	if err != nil {
+		if strings.Contains(err.Error(), "operation not permitted") {
+			return 0, errors.New("operation requires root priveleges")
+		}
+		return 0, err
+	}
+
+	if !received {
+		return 0, errors.New("timed out with IP " + addr)
+	}
+
+	return measured, nil
+}
+
+// ProbePeers - check liveness of every peer not seen within specified duration, recording round
+// trip time or failure in peer record; returns number of live peers probed
+func (db *NodeDatabase) ProbePeers(maxAge time.Duration) int {
+	live := 0
+
+	for _, peer := range db.ListPeers() {
+		if time.Since(peer.LastSeen) < maxAge {
+			continue
+		}
+
+		rtt, err := CheckLiveness(peer.Addr(), peer.ID)
+
+		if err != nil {
+			db.RecordFailure(peer.ID)
+			continue
+		}
+
+		db.RecordContact(peer.ID, rtt)
+		live++
+	}
+
+	return live
+}
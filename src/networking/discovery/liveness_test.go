@@ -0,0 +1,39 @@
+package discovery
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestCheckLiveness(t *testing.T) {
+	defer func(checks []LivenessCheck) { Liveness = checks }(Liveness)
+
+	errMismatch := errors.New("peer identity does not match expected node id")
+	errRefused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	check := func(rtt time.Duration, err error) LivenessCheck {
+		return func(addr string, id NodeID) (time.Duration, error) { return rtt, err }
+	}
+
+	tests := []struct {
+		name   string
+		checks []LivenessCheck
+		rtt    time.Duration
+		err    error
+	}{
+		{"first check succeeds", []LivenessCheck{check(time.Millisecond, nil), check(time.Second, nil)}, time.Millisecond, nil},
+		{"connection failed", []LivenessCheck{check(0, errRefused), check(time.Second, nil)}, time.Second, nil},
+		{"node id mismatch", []LivenessCheck{check(0, errMismatch), check(time.Second, nil)}, 0, errMismatch},
+		{"all checks fail", []LivenessCheck{check(0, errRefused), check(0, errRefused)}, 0, errRefused},
+	}
+
+	for _, test := range tests {
+		Liveness = test.checks
+
+		if rtt, err := CheckLiveness("1.1.1.1", RandomNodeID()); rtt != test.rtt || err != test.err {
+			t.Errorf("%s: expected %v (%v), got %v (%v)", test.name, test.rtt, test.err, rtt, err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package discovery
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
+	"math/big"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+)
+
+// NewNodeKey - generate new node identity key; node ID is derived from public half
+func NewNodeKey() (*ecdsa.PrivateKey, error) {
+	return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+}
+
+// PubkeyToNodeID - derive node ID from specified identity key (uncompressed X || Y coordinates)
+func PubkeyToNodeID(pub *ecdsa.PublicKey) NodeID {
+	var id NodeID
+	pub.X.FillBytes(id[:len(id)/2])
+	pub.Y.FillBytes(id[len(id)/2:])
+	return id
+}
+
+// Pubkey - recover public identity key from node ID, returning error if ID is not a valid key
+func (id NodeID) Pubkey() (*ecdsa.PublicKey, error) {
+	_, err := ecdh.P256().NewPublicKey(append([]byte{4}, id[:]...)) // Validate point
+
+	if err != nil {
+		return nil, errors.New("invalid node id: not a valid identity key")
+	}
+
+	x := new(big.Int).SetBytes(id[:len(id)/2])
+	y := new(big.Int).SetBytes(id[len(id)/2:])
+
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+}
+
+// WriteNodeKeyToMemory - create serialized instance of specified node key in specified path (string)
+func WriteNodeKeyToMemory(key *ecdsa.PrivateKey, path string) error {
+	b, err := x509.MarshalECPrivateKey(key)
+
+	if err != nil {
+		return err
+	}
+
+	return common.WriteGob(path+"nodeKey.gob", b)
+}
+
+// ReadNodeKeyFromMemory - read serialized node key from specified path
+func ReadNodeKeyFromMemory(path string) (*ecdsa.PrivateKey, error) {
+	var b []byte
+
+	err := common.ReadGob(path+"nodeKey.gob", &b)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return x509.ParseECPrivateKey(b)
+}
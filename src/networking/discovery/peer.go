@@ -0,0 +1,97 @@
+package discovery
+
+import (
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+const (
+	// PeerSourceBootstrap - peer learned from bootstrap node list
+	PeerSourceBootstrap = "bootstrap"
+
+	// PeerSourceDiscovery - peer learned from node lookup
+	PeerSourceDiscovery = "discovery"
+
+	// PeerSourceHandshake - peer learned from authenticated inbound connection
+	PeerSourceHandshake = "handshake"
+
+	// PeerSourceManual - peer added manually (via AddNode)
+	PeerSourceManual = "manual"
+
+	// PeerSourceLegacy - peer migrated from legacy node database format
+	PeerSourceLegacy = "legacy"
+)
+
+// Peer - record of single known node
+type Peer struct {
+	ID        NodeID   `json:"id"`
+	Addresses []string `json:"addresses"` // Most recently confirmed address last
+
+	FirstSeen time.Time     `json:"firstseen"`
+	LastSeen  time.Time     `json:"lastseen"`
+	LastRTT   time.Duration `json:"lastrtt"`
+
+	Failures   int `json:"failures"`   // Consecutive failed contacts
+	Reputation int `json:"reputation"` // Accumulated reputation; increases with successful contacts
+
+	Source string `json:"source"` // Means by which peer was learned
+}
+
+// Addr - return most recently confirmed address of peer
+func (peer *Peer) Addr() string {
+	if len(peer.Addresses) == 0 {
+		return ""
+	}
+	return peer.Addresses[len(peer.Addresses)-1]
+}
+
+// addAddress - record address as most recently confirmed address of peer
+func (peer *Peer) addAddress(addr string) {
+	if addr == "" {
+		return
+	}
+
+	for x, existing := range peer.Addresses {
+		if existing == addr {
+			peer.Addresses = append(peer.Addresses[:x:x], peer.Addresses[x+1:]...)
+			break
+		}
+	}
+
+	peer.Addresses = append(peer.Addresses, addr)
+}
+
+// copy - return deep copy of peer record
+func (peer *Peer) copy() *Peer {
+	cp := *peer
+	cp.Addresses = append([]string(nil), peer.Addresses...)
+	return &cp
+}
+
+// String - return hex encoding of node ID
+func (id NodeID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// MarshalText - encode node ID as hex (used as map key in serialized node database)
+func (id NodeID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText - decode node ID from hex
+func (id *NodeID) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(string(text))
+
+	if err != nil {
+		return err
+	}
+
+	if len(b) != len(id) {
+		return errors.New("invalid node id length")
+	}
+
+	copy(id[:], b)
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package discovery
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// SelectionPolicy - rules used to rank peers when selecting nodes to connect to
+type SelectionPolicy struct {
+	MaxFailures int // Peers with more consecutive failures are not selected
+
+	UnknownRTT         time.Duration // Round trip time assumed for peers never measured
+	FailurePenalty     time.Duration // Added to round trip time per consecutive failure
+	ReputationBonus    time.Duration // Subtracted from round trip time per reputation point
+	MaxReputationBonus time.Duration // Maximum total reputation bonus
+
+	Randomization float64 // Maximum fraction by which score is randomly increased, spreading load between peers
+}
+
+// Selection - policy used to select peers
+var Selection = SelectionPolicy{
+	MaxFailures:        5,
+	UnknownRTT:         500 * time.Millisecond,
+	FailurePenalty:     250 * time.Millisecond,
+	ReputationBonus:    time.Millisecond,
+	MaxReputationBonus: 100 * time.Millisecond,
+	Randomization:      0.25,
+}
+
+// Score - rank peer by expected responsiveness; lower is better
+func (policy SelectionPolicy) Score(peer *Peer) time.Duration {
+	score := peer.LastRTT
+
+	if score <= 0 {
+		score = policy.UnknownRTT
+	}
+
+	score += time.Duration(peer.Failures) * policy.FailurePenalty
+
+	bonus := time.Duration(peer.Reputation) * policy.ReputationBonus
+
+	if bonus > policy.MaxReputationBonus {
+		bonus = policy.MaxReputationBonus
+	}
+
+	score -= bonus
+
+	if score < 0 {
+		score = 0
+	}
+
+	return score
+}
+
+// SelectPeers - select up to count reachable peers, best first according to selection policy
+func (db *NodeDatabase) SelectPeers(count int) []*Peer {
+	type ranked struct {
+		peer  *Peer
+		score float64
+	}
+
+	var candidates []ranked
+
+	for _, peer := range db.ListPeers() {
+		if peer.Addr() == "" || peer.Failures > Selection.MaxFailures || db.IsBanned(peer.Addr(), peer.ID) {
+			continue
+		}
+
+		score := float64(Selection.Score(peer)) * (1 + rand.Float64()*Selection.Randomization)
+		candidates = append(candidates, ranked{peer: peer, score: score})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].score < candidates[j].score
+	})
+
+	if len(candidates) > count {
+		candidates = candidates[:count]
+	}
+
+	peers := make([]*Peer, len(candidates))
+
+	for x, candidate := range candidates {
+		peers[x] = candidate.peer
+	}
+
+	return peers
+}
+
+// FindNodes - find up to count best nodes to connect to, returns ip addresses
+func (db *NodeDatabase) FindNodes(count int) []string {
+	var addrs []string
+
+	for _, peer := range db.SelectPeers(count) {
+		addrs = append(addrs, peer.Addr())
+	}
+
+	return addrs
+}
@@ -0,0 +1,52 @@
+package discovery
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name  string
+		peer  Peer
+		score time.Duration
+	}{
+		{"unmeasured", Peer{}, Selection.UnknownRTT},
+		{"measured", Peer{LastRTT: 10 * time.Millisecond}, 10 * time.Millisecond},
+		{"failing", Peer{LastRTT: 10 * time.Millisecond, Failures: 2}, 510 * time.Millisecond},
+		{"reputable", Peer{LastRTT: 50 * time.Millisecond, Reputation: 20}, 30 * time.Millisecond},
+		{"reputation capped", Peer{LastRTT: 50 * time.Millisecond, Reputation: 1000}, 0},
+	}
+
+	for _, test := range tests {
+		if score := Selection.Score(&test.peer); score != test.score {
+			t.Errorf("%s: expected score %s, got %s", test.name, test.score, score)
+		}
+	}
+}
+
+func TestSelectPeers(t *testing.T) {
+	db := &NodeDatabase{SelfRef: RandomNodeID()}
+
+	fast, slow, failing := RandomNodeID(), RandomNodeID(), RandomNodeID()
+
+	db.AddPeer(&Peer{ID: slow, Addresses: []string{"1.1.1.1"}})
+	db.AddPeer(&Peer{ID: fast, Addresses: []string{"2.2.2.2"}})
+	db.AddPeer(&Peer{ID: failing, Addresses: []string{"3.3.3.3"}})
+
+	db.RecordContact(slow, 400*time.Millisecond)
+	db.RecordContact(fast, 10*time.Millisecond)
+	db.RecordContact(failing, time.Millisecond)
+
+	for x := 0; x <= Selection.MaxFailures; x++ {
+		db.RecordFailure(failing)
+	}
+
+	if best := db.FindNode(); best != "2.2.2.2" {
+		t.Errorf("lowest latency node not selected; got %s", best)
+	}
+
+	if peers := db.FindNodes(3); len(peers) != 2 || peers[1] != "1.1.1.1" {
+		t.Errorf("unexpected fan-out selection: %v", peers)
+	}
+}
@@ -0,0 +1,318 @@
+package discovery
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"math/bits"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// BucketSize - maximum number of live entries held in single routing table bucket
+	BucketSize = 16
+
+	// lookupAlpha - number of parallel queries issued during node lookup
+	lookupAlpha = 3
+
+	// hashBits - number of bits in hashed node ID used for distance calculation
+	hashBits = sha256.Size * 8
+
+	// targetAttempts - maximum number of random IDs generated when searching for ID at given distance
+	targetAttempts = 1 << 16
+)
+
+// TableEntry - reference to node held in routing table
+type TableEntry struct {
+	ID       NodeID    `json:"id"`
+	Addr     string    `json:"addr"`
+	LastSeen time.Time `json:"lastseen"`
+}
+
+// Table - Kademlia-style routing table; nodes are grouped in buckets by XOR distance from self
+type Table struct {
+	self NodeID
+
+	mu           sync.Mutex
+	buckets      [hashBits + 1][]TableEntry // Bucket index is log distance from self
+	replacements [hashBits + 1][]TableEntry // Candidates promoted once live entries are removed
+	refreshed    [hashBits + 1]time.Time
+}
+
+// NewTable - return new, empty routing table centered on self
+func NewTable(self NodeID) *Table {
+	return &Table{self: self}
+}
+
+// Self - node ID table is centered on
+func (tab *Table) Self() NodeID {
+	return tab.self
+}
+
+// LogDistance - logarithmic XOR distance between two node IDs (hashed); 0 if equal
+func LogDistance(a NodeID, b NodeID) int {
+	ha, hb := sha256.Sum256(a[:]), sha256.Sum256(b[:])
+
+	for x := range ha {
+		if diff := ha[x] ^ hb[x]; diff != 0 {
+			return hashBits - x*8 - bits.LeadingZeros8(diff)
+		}
+	}
+
+	return 0
+}
+
+// closer - check if a is closer to target than b by XOR distance
+func closer(target NodeID, a NodeID, b NodeID) bool {
+	ht, ha, hb := sha256.Sum256(target[:]), sha256.Sum256(a[:]), sha256.Sum256(b[:])
+
+	for x := range ht {
+		da, db := ha[x]^ht[x], hb[x]^ht[x]
+
+		if da != db {
+			return da < db
+		}
+	}
+
+	return false
+}
+
+// Add - add node (or mark node as seen) in routing table; nodes found while bucket is full are kept as replacements
+func (tab *Table) Add(entry TableEntry) {
+	if entry.ID == tab.self || entry.ID == (NodeID{}) || entry.Addr == "" {
+		return
+	}
+
+	if entry.LastSeen.IsZero() {
+		entry.LastSeen = time.Now().UTC()
+	}
+
+	tab.mu.Lock()
+	defer tab.mu.Unlock()
+
+	b := LogDistance(tab.self, entry.ID)
+
+	for x, existing := range tab.buckets[b] {
+		if existing.ID == entry.ID {
+			// Move to back of bucket (most recently seen)
+			tab.buckets[b] = append(append(tab.buckets[b][:x:x], tab.buckets[b][x+1:]...), entry)
+			return
+		}
+	}
+
+	if len(tab.buckets[b]) < BucketSize {
+		tab.buckets[b] = append(tab.buckets[b], entry)
+		tab.replacements[b] = removeEntry(tab.replacements[b], entry.ID)
+		return
+	}
+
+	tab.replacements[b] = append(removeEntry(tab.replacements[b], entry.ID), entry)
+
+	if len(tab.replacements[b]) > BucketSize {
+		tab.replacements[b] = tab.replacements[b][1:]
+	}
+}
+
+// Remove - remove node from routing table, promoting most recently seen replacement
+func (tab *Table) Remove(id NodeID) {
+	tab.mu.Lock()
+	defer tab.mu.Unlock()
+
+	b := LogDistance(tab.self, id)
+	before := len(tab.buckets[b])
+
+	tab.buckets[b] = removeEntry(tab.buckets[b], id)
+
+	if len(tab.buckets[b]) < before && len(tab.replacements[b]) > 0 {
+		last := len(tab.replacements[b]) - 1
+		tab.buckets[b] = append(tab.buckets[b], tab.replacements[b][last])
+		tab.replacements[b] = tab.replacements[b][:last]
+	}
+}
+
+// Len - number of live nodes held in table
+func (tab *Table) Len() int {
+	tab.mu.Lock()
+	defer tab.mu.Unlock()
+
+	count := 0
+
+	for _, bucket := range tab.buckets {
+		count += len(bucket)
+	}
+
+	return count
+}
+
+// Closest - return up to count nodes held in table, closest to target first
+func (tab *Table) Closest(target NodeID, count int) []TableEntry {
+	tab.mu.Lock()
+
+	var entries []TableEntry
+
+	for _, bucket := range tab.buckets {
+		entries = append(entries, bucket...)
+	}
+
+	tab.mu.Unlock()
+
+	sortByDistance(target, entries)
+
+	if len(entries) > count {
+		entries = entries[:count]
+	}
+
+	return entries
+}
+
+// Lookup - iteratively query nodes closest to target for their neighbors, returning closest nodes found
+//
+// Each queried node that responds is added to the table; nodes that fail to respond are removed.
+func (tab *Table) Lookup(target NodeID, query func(TableEntry) ([]TableEntry, error)) []TableEntry {
+	tab.markRefreshed(target)
+
+	result := tab.Closest(target, BucketSize)
+
+	asked := map[NodeID]bool{tab.self: true}
+	seen := map[NodeID]bool{tab.self: true}
+
+	for _, entry := range result {
+		seen[entry.ID] = true
+	}
+
+	type reply struct {
+		from      TableEntry
+		neighbors []TableEntry
+		err       error
+	}
+
+	for {
+		var pending []TableEntry
+
+		for _, entry := range result {
+			if len(pending) == lookupAlpha {
+				break
+			}
+
+			if !asked[entry.ID] {
+				asked[entry.ID] = true
+				pending = append(pending, entry)
+			}
+		}
+
+		if len(pending) == 0 {
+			return result
+		}
+
+		replies := make(chan reply, len(pending))
+
+		for _, entry := range pending {
+			go func(entry TableEntry) {
+				neighbors, err := query(entry)
+				replies <- reply{from: entry, neighbors: neighbors, err: err}
+			}(entry)
+		}
+
+		for range pending {
+			r := <-replies
+
+			if r.err != nil {
+				tab.Remove(r.from.ID)
+				result = removeEntry(result, r.from.ID)
+				continue
+			}
+
+			tab.Add(TableEntry{ID: r.from.ID, Addr: r.from.Addr})
+
+			for _, neighbor := range r.neighbors {
+				if !seen[neighbor.ID] && neighbor.ID != (NodeID{}) && neighbor.Addr != "" {
+					seen[neighbor.ID] = true
+					result = append(result, neighbor)
+				}
+			}
+		}
+
+		sortByDistance(target, result)
+
+		if len(result) > BucketSize {
+			result = result[:BucketSize]
+		}
+	}
+}
+
+// RefreshTargets - return random lookup targets for buckets not refreshed within specified interval
+func (tab *Table) RefreshTargets(interval time.Duration) []NodeID {
+	tab.mu.Lock()
+
+	var stale []int
+
+	now := time.Now()
+
+	for b := range tab.buckets {
+		if len(tab.buckets[b]) > 0 && now.Sub(tab.refreshed[b]) > interval {
+			stale = append(stale, b)
+			tab.refreshed[b] = now
+		}
+	}
+
+	tab.mu.Unlock() // Targets generated without holding lock, as search may take a while
+
+	targets := make([]NodeID, 0, len(stale))
+
+	for _, b := range stale {
+		targets = append(targets, RandomNodeIDAt(tab.self, b)) // Unknown node, so lookup discovers new nodes
+	}
+
+	return targets
+}
+
+// RandomNodeID - generate random node ID, used as lookup target to explore network
+func RandomNodeID() NodeID {
+	var id NodeID
+	rand.Read(id[:])
+	return id
+}
+
+// RandomNodeIDAt - generate random node ID at specified log distance from self; as distance is
+// measured between hashed IDs, IDs are generated until one lies at distance, returning closest
+// match if none is found within targetAttempts (likely only for very close distances)
+func RandomNodeIDAt(self NodeID, distance int) NodeID {
+	best, bestDiff := RandomNodeID(), hashBits+1
+
+	for x := 0; x < targetAttempts && bestDiff != 0; x++ {
+		id := RandomNodeID()
+		diff := LogDistance(self, id) - distance
+
+		if diff < 0 {
+			diff = -diff
+		}
+
+		if diff < bestDiff {
+			best, bestDiff = id, diff
+		}
+	}
+
+	return best
+}
+
+func (tab *Table) markRefreshed(target NodeID) {
+	tab.mu.Lock()
+	tab.refreshed[LogDistance(tab.self, target)] = time.Now()
+	tab.mu.Unlock()
+}
+
+func sortByDistance(target NodeID, entries []TableEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return closer(target, entries[i].ID, entries[j].ID)
+	})
+}
+
+func removeEntry(entries []TableEntry, id NodeID) []TableEntry {
+	for x, entry := range entries {
+		if entry.ID == id {
+			return append(entries[:x:x], entries[x+1:]...)
+		}
+	}
+	return entries
+}
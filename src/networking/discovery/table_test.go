@@ -0,0 +1,104 @@
+package discovery
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRoutingTableLookup(t *testing.T) {
+	// Simulated network of nodes, each knowing every other node
+	var network []TableEntry
+
+	for x := 0; x < 64; x++ {
+		network = append(network, TableEntry{ID: RandomNodeID(), Addr: fmt.Sprintf("10.0.0.%d", x)})
+	}
+
+	respond := func(target NodeID) func(TableEntry) ([]TableEntry, error) {
+		return func(entry TableEntry) ([]TableEntry, error) {
+			neighbors := NewTable(entry.ID)
+			for _, node := range network {
+				neighbors.Add(node)
+			}
+			return neighbors.Closest(target, BucketSize), nil
+		}
+	}
+
+	tests := []struct {
+		name   string
+		target TableEntry
+	}{
+		{"first node", network[0]},
+		{"distant node", network[42]},
+		{"last node", network[63]},
+	}
+
+	for _, test := range tests {
+		table := NewTable(RandomNodeID())
+		table.Add(network[0])
+
+		found := table.Lookup(test.target.ID, respond(test.target.ID))
+
+		if len(found) == 0 || found[0].ID != test.target.ID {
+			t.Errorf("%s: lookup did not find target node", test.name)
+		}
+
+		if table.Len() <= 1 {
+			t.Errorf("%s: responding nodes not added to table", test.name)
+		}
+	}
+}
+
+func TestLogDistance(t *testing.T) {
+	self := RandomNodeID()
+
+	if LogDistance(self, self) != 0 {
+		t.Errorf("distance to self not zero")
+	}
+
+	if other := RandomNodeID(); LogDistance(self, other) != LogDistance(other, self) || LogDistance(self, other) == 0 {
+		t.Errorf("distance between distinct nodes not symmetric & positive")
+	}
+}
+
+func TestRefreshTargets(t *testing.T) {
+	self := RandomNodeID()
+	table := NewTable(self)
+	known := make(map[NodeID]bool)
+
+	for x := 0; x < 32; x++ {
+		entry := TableEntry{ID: RandomNodeID(), Addr: fmt.Sprintf("1.1.1.%d", x)}
+		table.Add(entry)
+		known[entry.ID] = true
+	}
+
+	targets := table.RefreshTargets(0)
+
+	if len(targets) == 0 {
+		t.Fatalf("no refresh targets for populated buckets")
+	}
+
+	for _, target := range targets {
+		distance := LogDistance(self, target)
+
+		if known[target] {
+			t.Errorf("refresh target is known node")
+		}
+
+		if len(table.buckets[distance]) == 0 {
+			t.Errorf("refresh target at distance %d of empty bucket", distance)
+		}
+	}
+
+	if targets := table.RefreshTargets(time.Hour); len(targets) != 0 {
+		t.Errorf("recently refreshed buckets targeted again")
+	}
+
+	tests := []int{hashBits, hashBits - 1, hashBits - 4}
+
+	for _, distance := range tests {
+		if id := RandomNodeIDAt(self, distance); LogDistance(self, id) != distance {
+			t.Errorf("generated id at distance %d, expected %d", LogDistance(self, id), distance)
+		}
+	}
+}
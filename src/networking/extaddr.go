@@ -0,0 +1,142 @@
+package networking
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+)
+
+const (
+	// addrVoteExpiry - time after which reported external address no longer counts
+	addrVoteExpiry = time.Hour
+
+	// addrQueryPeers - number of peers contacted when discovering external address
+	addrQueryPeers = 8
+)
+
+// AddrVoteQuorum - minimum number of peers that must agree on external address before it is adopted
+var AddrVoteQuorum = 2
+
+// ErrExternalAddrUnknown - returned when peers have not agreed on external address of current node
+var ErrExternalAddrUnknown = errors.New("external address unknown; not enough peers agree")
+
+var externalAddrs = &addrVotes{votes: make(map[string]addrVote)}
+
+// addrVote - external address of current node as reported by single peer
+type addrVote struct {
+	id   discovery.NodeID // Node ID proven by voting peer
+	addr string
+	time time.Time
+}
+
+// addrVotes - external addresses reported by peers, one vote per peer address & node ID
+type addrVotes struct {
+	mu    sync.Mutex
+	votes map[string]addrVote
+}
+
+// vote - record address reported by peer at specified address proving specified ID, replacing
+// previous vote of that address or ID
+func (votes *addrVotes) vote(voter string, id discovery.NodeID, addr string) {
+	votes.mu.Lock()
+	defer votes.mu.Unlock()
+
+	for other, vote := range votes.votes {
+		if vote.id == id {
+			delete(votes.votes, other) // Node voting from several addresses counts once
+		}
+	}
+
+	votes.votes[voter] = addrVote{id: id, addr: addr, time: time.Now()}
+}
+
+// majority - address reported by most peers, if reported by quorum & more than half of voting peers
+func (votes *addrVotes) majority() (string, bool) {
+	votes.mu.Lock()
+	defer votes.mu.Unlock()
+
+	counts := make(map[string]int)
+	total := 0
+
+	for voter, vote := range votes.votes {
+		if time.Since(vote.time) > addrVoteExpiry {
+			delete(votes.votes, voter)
+			continue
+		}
+
+		counts[vote.addr]++
+		total++
+	}
+
+	best, bestCount := "", 0
+
+	for addr, count := range counts {
+		if count > bestCount || (count == bestCount && addr < best) {
+			best, bestCount = addr, count
+		}
+	}
+
+	if bestCount < AddrVoteQuorum || bestCount*2 <= total {
+		return "", false
+	}
+
+	return best, true
+}
+
+// ExternalAddr - external address of current node agreed on by peers
+func ExternalAddr() (string, bool) {
+	return externalAddrs.majority()
+}
+
+// observeExternalAddr - count address reported during handshake by peer current node dialed towards
+// external address of current node, updating node database once majority of peers agree on a new
+// address; only outbound connections count, as inbound peers choose to connect (possibly many times)
+func observeExternalAddr(Db *discovery.NodeDatabase, voter string, id discovery.NodeID, observed string) {
+	if voter == "" || id == (discovery.NodeID{}) || discovery.Addressing.Check(observed) != nil {
+		return // Peers on local network observe local address
+	}
+
+	ip, _ := discovery.ParseAddr(observed)
+	externalAddrs.vote(voter, id, ip.String())
+
+	if addr, found := externalAddrs.majority(); found && Db != nil && Db.SetSelfAddr(addr) {
+		common.ThrowSuccess("peers agree on external address " + addr)
+	}
+}
+
+// DiscoverExternalAddr - determine external address of current node by contacting known peers &
+// bootstrap nodes, each of which reports the address it observes during handshake
+func DiscoverExternalAddr(Db *discovery.NodeDatabase) (string, error) {
+	candidates := append(Db.FindNodes(addrQueryPeers), Db.BootstrapNodeAddrs...)
+
+	var wg sync.WaitGroup
+
+	for _, addr := range candidates {
+		wg.Add(1)
+
+		go func(addr string) {
+			defer wg.Done()
+
+			_, err := PingNode(addr, Db.PeerID(addr))
+
+			if err != nil {
+				common.ThrowWarning("could not reach " + addr + " for address discovery: " + err.Error())
+			}
+		}(addr)
+	}
+
+	wg.Wait()
+
+	addr, found := ExternalAddr()
+
+	if !found {
+		return "", ErrExternalAddrUnknown
+	}
+
+	Db.SetSelfAddr(addr)
+
+	return addr, nil
+}
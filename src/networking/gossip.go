@@ -0,0 +1,177 @@
+package networking
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/core/types"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+)
+
+// GossipConfig - parameters controlling propagation of transactions through network
+type GossipConfig struct {
+	Fanout     int           // Number of peers each transaction is forwarded to
+	MaxHops    int           // Number of times transaction may be forwarded before being dropped
+	SeenExpiry time.Duration // Time transaction hash is remembered after being seen
+	SeenLimit  int           // Maximum number of remembered transaction hashes
+}
+
+// Gossip - gossip configuration used for relaying & forwarding transactions
+var Gossip = GossipConfig{Fanout: 4, MaxHops: 6, SeenExpiry: 30 * time.Minute, SeenLimit: 100000}
+
+var seenTxs = &seenCache{entries: make(map[common.Hash]time.Time)}
+
+// seenCache - recently seen transaction hashes, used to forward each transaction once
+type seenCache struct {
+	mu      sync.Mutex
+	entries map[common.Hash]time.Time
+}
+
+// markSeen - record hash as seen, returning false if hash was already seen
+func (cache *seenCache) markSeen(hash common.Hash) bool {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	now := time.Now()
+
+	if seenAt, found := cache.entries[hash]; found && now.Sub(seenAt) < Gossip.SeenExpiry {
+		return false
+	}
+
+	if len(cache.entries) >= Gossip.SeenLimit {
+		cache.prune(now)
+	}
+
+	cache.entries[hash] = now
+
+	return true
+}
+
+// has - check if hash has been seen & not yet expired
+func (cache *seenCache) has(hash common.Hash) bool {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	seenAt, found := cache.entries[hash]
+
+	return found && time.Since(seenAt) < Gossip.SeenExpiry
+}
+
+// prune - remove expired hashes, then oldest hashes until cache is below limit
+func (cache *seenCache) prune(now time.Time) {
+	for hash, seenAt := range cache.entries {
+		if now.Sub(seenAt) >= Gossip.SeenExpiry {
+			delete(cache.entries, hash)
+		}
+	}
+
+	for len(cache.entries) >= Gossip.SeenLimit && len(cache.entries) > 0 {
+		var oldest common.Hash
+		oldestTime := now
+
+		for hash, seenAt := range cache.entries {
+			if !seenAt.After(oldestTime) {
+				oldest = hash
+				oldestTime = seenAt
+			}
+		}
+
+		delete(cache.entries, oldest)
+	}
+}
+
+// gossipTx - forward transaction of chain with specified identifier to configured number of peers,
+// allowing ttl further hops
+func gossipTx(Tx *types.Transaction, chainID common.Identifier, Db *discovery.NodeDatabase, ttl int, exclude string) error {
+	seenTxs.markSeen(Tx.Hash())
+
+	if ttl <= 0 {
+		return errors.New("transaction reached maximum hop count; not forwarding")
+	}
+
+	txBytes := new(bytes.Buffer)
+	err := json.NewEncoder(txBytes).Encode(Tx)
+
+	if err != nil {
+		return err
+	}
+
+	peers := gossipPeers(Db, Gossip.Fanout, exclude)
+
+	if len(peers) == 0 {
+		return errors.New("no peers available for relay")
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(peers))
+
+	for _, peer := range peers {
+		conn := newConnection(Db.SelfAddr, peer, "relay", txBytes.Bytes())
+		conn.TTL = ttl
+		conn.ChainID = chainID
+
+		wg.Add(1)
+
+		go func(conn *Connection) {
+			defer wg.Done()
+			errs <- conn.attempt(Db)
+		}(conn)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	var lastErr error
+	sent := 0
+
+	for err := range errs {
+		if err != nil {
+			common.ThrowWarning("relay to peer failed: " + err.Error())
+			lastErr = err
+		} else {
+			sent++
+		}
+	}
+
+	if sent == 0 {
+		return lastErr
+	}
+
+	return nil
+}
+
+// forwardRelay - forward received transaction relay onward if not seen before & hops remain
+func forwardRelay(conn *Connection, Tx *types.Transaction, Db *discovery.NodeDatabase) {
+	if conn.TTL <= 1 || Db == nil {
+		return
+	}
+
+	err := gossipTx(Tx, conn.ChainID, Db, conn.TTL-1, conn.InitNodeAddr)
+
+	if err != nil {
+		common.ThrowWarning("error while forwarding transaction: " + err.Error())
+	}
+}
+
+// gossipPeers - select up to count known peers according to selection policy, excluding self & specified address
+func gossipPeers(Db *discovery.NodeDatabase, count int, exclude string) []string {
+	var candidates []string
+
+	for _, addr := range Db.FindNodes(count + 1) {
+		if addr != Db.SelfAddr && addr != exclude && len(candidates) < count {
+			candidates = append(candidates, addr)
+		}
+	}
+
+	if len(candidates) == 0 {
+		if best := Db.FindNode(); best != "" && best != exclude {
+			candidates = append(candidates, best)
+		}
+	}
+
+	return candidates
+}
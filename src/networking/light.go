@@ -0,0 +1,184 @@
+package networking
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/core/types"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+)
+
+const (
+	// HeaderBatchSize - number of checkpoint headers requested per header batch
+	HeaderBatchSize = 500
+
+	// maxHeaderBatchSize - maximum number of checkpoint headers served in single batch
+	maxHeaderBatchSize = 2000
+)
+
+// HeaderRequest - request for checkpoint headers following specified checkpoint number
+type HeaderRequest struct {
+	After int `json:"after"`
+	Limit int `json:"limit"`
+}
+
+// HeaderBatch - page of checkpoint headers following requested checkpoint
+type HeaderBatch struct {
+	Headers []*types.Header `json:"headers"`
+	More    bool            `json:"more"`
+}
+
+// AccountRequest - request for checkpointed transactions of account, with inclusion proofs
+type AccountRequest struct {
+	Address common.Address `json:"address"`
+}
+
+// AccountResponse - transactions sent or received by account & their inclusion proofs; Error is
+// set if serving node could not produce proofs
+type AccountResponse struct {
+	Transactions []*types.Transaction `json:"transactions"`
+	Proofs       []*types.TxProof     `json:"proofs"`
+	Error        string               `json:"error,omitempty"`
+}
+
+// RequestHeaders - request checkpoint headers of chain following specified checkpoint number from
+// node; like FetchChain, but without transactions. Headers must still be verified (see types.VerifyHeaders).
+func RequestHeaders(Db *discovery.NodeDatabase, node string, chainID common.Identifier, after int, limit int) (*HeaderBatch, error) {
+	resp, err := lightRequest(Db, node, chainID, "headerrequest", "headers", HeaderRequest{After: after, Limit: limit})
+
+	if err != nil {
+		return nil, err
+	}
+
+	batch := HeaderBatch{}
+	err = json.NewDecoder(bytes.NewReader(resp.Data)).Decode(&batch)
+
+	if err != nil {
+		Db.Misbehave(node, resp.PeerID, discovery.ViolationUndecodable)
+		return nil, err
+	}
+
+	return &batch, nil
+}
+
+// RequestAccount - request checkpointed transactions of account & their inclusion proofs from node;
+// proofs must still be verified against checkpoints they refer to
+func RequestAccount(Db *discovery.NodeDatabase, node string, chainID common.Identifier, addr common.Address) (*AccountResponse, error) {
+	resp, err := lightRequest(Db, node, chainID, "accountrequest", "account", AccountRequest{Address: addr})
+
+	if err != nil {
+		return nil, err
+	}
+
+	account := AccountResponse{}
+	err = json.NewDecoder(bytes.NewReader(resp.Data)).Decode(&account)
+
+	if err != nil {
+		Db.Misbehave(node, resp.PeerID, discovery.ViolationUndecodable)
+		return nil, err
+	}
+
+	if account.Error != "" {
+		return nil, errors.New(account.Error)
+	}
+
+	if len(account.Transactions) != len(account.Proofs) {
+		Db.Misbehave(node, resp.PeerID, discovery.ViolationInvalidChain)
+		return nil, errors.New("account response without proof for every transaction")
+	}
+
+	return &account, nil
+}
+
+// lightRequest - send request of specified type for chain to node, returning response of expected type
+func lightRequest(Db *discovery.NodeDatabase, node string, chainID common.Identifier, reqType ConnectionType, respType ConnectionType, req interface{}) (*Connection, error) {
+	reqBytes, err := json.Marshal(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	conn := newConnection(Db.SelfAddr, node, reqType, reqBytes)
+	conn.ChainID = chainID
+
+	resp, err := conn.request(Db.PeerID(node), Db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Type != respType {
+		return nil, errors.New("unexpected response to " + string(reqType) + ": " + string(resp.Type))
+	}
+
+	return resp, nil
+}
+
+// handleHeaderRequest - respond to header request with checkpoint headers of local chain
+func handleHeaderRequest(conn *Connection, Ch *types.Chain, connec net.Conn) error {
+	req := HeaderRequest{}
+	err := json.NewDecoder(bytes.NewReader(conn.Data)).Decode(&req)
+
+	if err != nil {
+		return err
+	}
+
+	if req.Limit <= 0 || req.Limit > maxHeaderBatchSize {
+		req.Limit = maxHeaderBatchSize
+	}
+
+	batch := HeaderBatch{}
+
+	if req.After >= 0 && req.After < len(Ch.Checkpoints) {
+		batch.Headers = Ch.Checkpoints[req.After:]
+
+		if len(batch.Headers) > req.Limit {
+			batch.Headers, batch.More = batch.Headers[:req.Limit], true
+		}
+	}
+
+	return respondLight(conn, Ch, connec, "headers", batch)
+}
+
+// handleAccountRequest - respond to account request with account's transactions & proofs from local chain
+func handleAccountRequest(conn *Connection, Ch *types.Chain, connec net.Conn) error {
+	req := AccountRequest{}
+	err := json.NewDecoder(bytes.NewReader(conn.Data)).Decode(&req)
+
+	if err != nil {
+		return err
+	}
+
+	resp := AccountResponse{}
+
+	resp.Transactions, resp.Proofs, err = Ch.ProveAccount(req.Address)
+
+	if err != nil {
+		resp.Error = err.Error()
+	}
+
+	return respondLight(conn, Ch, connec, "account", resp)
+}
+
+// respondLight - write response of specified type for chain to requesting peer
+func respondLight(conn *Connection, Ch *types.Chain, connec net.Conn, respType ConnectionType, data interface{}) error {
+	respBytes, err := json.Marshal(data)
+
+	if err != nil {
+		return err
+	}
+
+	selfAddr := ""
+
+	if Ch.NodeDb != nil {
+		selfAddr = Ch.NodeDb.SelfAddr
+	}
+
+	resp := newConnection(selfAddr, conn.InitNodeAddr, respType, respBytes)
+	resp.ChainID = Ch.Identifier
+
+	return respond(connec, resp)
+}
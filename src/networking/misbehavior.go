@@ -0,0 +1,66 @@
+package networking
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/core/types"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+)
+
+// MaxMessageSize - maximum size of single message received from peer
+var MaxMessageSize int64 = 64 << 20
+
+// ErrMessageTooLarge - returned when peer sends message exceeding maximum message size
+var ErrMessageTooLarge = errors.New("message exceeds maximum message size")
+
+// misbehave - record protocol violation committed by peer on other end of connection; node
+// database is persisted once peer is banned
+func misbehave(Db *discovery.NodeDatabase, connec net.Conn, violation discovery.Violation) {
+	if Db == nil || connec == nil {
+		return
+	}
+
+	id := discovery.NodeID{}
+
+	if sConn, ok := connec.(*SecureConn); ok {
+		id = sConn.RemoteID
+	}
+
+	if Db.Misbehave(remoteHost(connec), id, violation) {
+		Db.WriteDbToMemory(common.GetCurrentDir())
+	}
+}
+
+// readViolation - protocol violation indicated by error encountered while reading from peer, if any
+func readViolation(err error) (discovery.Violation, bool) {
+	switch err {
+	case ErrMessageTooLarge, ErrFrameTooLarge:
+		return discovery.ViolationOversized, true
+	case ErrFrameAuthFailed:
+		return discovery.ViolationInvalidSignature, true
+	}
+
+	return discovery.Violation{}, false
+}
+
+// txViolation - check if admission error shows transaction can never be valid (rather than
+// merely stale or already known)
+func txViolation(err error) bool {
+	return err == ErrNotWitnessed || err == ErrTxFuture
+}
+
+// decodeTx - decode transaction received from peer
+func decodeTx(b []byte) (*types.Transaction, error) {
+	tx := types.Transaction{}
+	err := json.NewDecoder(bytes.NewReader(b)).Decode(&tx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &tx, nil
+}
@@ -0,0 +1,278 @@
+package networking
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+	upnp "github.com/nebulouslabs/go-upnp"
+)
+
+// MapLifetime - lifetime requested for port mappings; mappings are renewed at half their lifetime
+var MapLifetime = 20 * time.Minute
+
+// ErrNoNAT - returned when no NAT traversal method is available
+var ErrNoNAT = errors.New("no nat gateway found")
+
+// NATManager - means of making node reachable from outside its local network
+type NATManager interface {
+	ExternalIP() (net.IP, error)                                    // Address node is reachable at from outside local network
+	AddMapping(port int, name string, lifetime time.Duration) error // Map external port (TCP & UDP) to same port on current machine
+	DeleteMapping(port int) error                                   // Remove port mapping
+	String() string
+}
+
+// UPnP - NAT manager using UPnP internet gateway device
+type UPnP struct {
+	IGD *upnp.IGD
+}
+
+// ExternalIP - external address of gateway device
+func (nat *UPnP) ExternalIP() (net.IP, error) {
+	addr, err := nat.IGD.ExternalIP()
+
+	if err != nil {
+		return nil, err
+	}
+
+	ip := net.ParseIP(strings.TrimSpace(addr))
+
+	if ip == nil {
+		return nil, errors.New("gateway returned invalid external address " + addr)
+	}
+
+	return ip, nil
+}
+
+// AddMapping - forward port through gateway device; UPnP mappings do not expire, so lifetime is unused
+func (nat *UPnP) AddMapping(port int, name string, lifetime time.Duration) error {
+	return nat.IGD.Forward(uint16(port), name)
+}
+
+// DeleteMapping - remove forwarded port from gateway device
+func (nat *UPnP) DeleteMapping(port int) error {
+	return nat.IGD.Clear(uint16(port))
+}
+
+func (nat *UPnP) String() string {
+	return "upnp"
+}
+
+// StaticNAT - NAT manager for nodes whose external address is known & whose ports are forwarded manually
+type StaticNAT struct {
+	IP net.IP
+}
+
+// ExternalIP - configured external address
+func (nat *StaticNAT) ExternalIP() (net.IP, error) {
+	return nat.IP, nil
+}
+
+// AddMapping - no-op; ports are forwarded manually
+func (nat *StaticNAT) AddMapping(port int, name string, lifetime time.Duration) error {
+	return nil
+}
+
+// DeleteMapping - no-op; ports are forwarded manually
+func (nat *StaticNAT) DeleteMapping(port int) error {
+	return nil
+}
+
+func (nat *StaticNAT) String() string {
+	return "extip:" + nat.IP.String()
+}
+
+// GetGateway - get reference to current network gateway device
+func GetGateway() (*upnp.IGD, error) {
+	// connect to router
+	return upnp.Discover()
+}
+
+// DiscoverNAT - find NAT traversal method supported by local network, trying UPnP, then PCP/NAT-PMP
+func DiscoverNAT() (NATManager, error) {
+	if igd, err := GetGateway(); err == nil {
+		return &UPnP{IGD: igd}, nil
+	}
+
+	gateway, err := DefaultGateway()
+
+	if err != nil {
+		return nil, ErrNoNAT
+	}
+
+	pmp := NewPMP(gateway)
+
+	if _, err := pmp.ExternalIP(); err != nil && err != ErrExternalIPUnknown {
+		return nil, ErrNoNAT
+	}
+
+	return pmp, nil
+}
+
+// ParseNAT - select NAT manager from specification: "none", "any" (discover), "upnp", "pmp",
+// "pmp:<gateway ip>" or "extip:<external ip>"; returns nil manager for "none"
+func ParseNAT(spec string) (NATManager, error) {
+	method, arg := spec, ""
+
+	if x := strings.Index(spec, ":"); x >= 0 {
+		method, arg = spec[:x], spec[x+1:]
+	}
+
+	switch strings.ToLower(method) {
+	case "", "none", "off":
+		return nil, nil
+	case "any", "auto":
+		return DiscoverNAT()
+	case "upnp":
+		igd, err := GetGateway()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &UPnP{IGD: igd}, nil
+	case "pmp", "natpmp", "pcp":
+		if arg == "" {
+			gateway, err := DefaultGateway()
+
+			if err != nil {
+				return nil, err
+			}
+
+			return NewPMP(gateway), nil
+		}
+
+		gateway := net.ParseIP(arg)
+
+		if gateway == nil {
+			return nil, errors.New("invalid gateway address " + arg)
+		}
+
+		return NewPMP(gateway), nil
+	case "extip":
+		ip := net.ParseIP(arg)
+
+		if ip == nil {
+			return nil, errors.New("invalid external address " + arg)
+		}
+
+		return &StaticNAT{IP: ip}, nil
+	}
+
+	return nil, errors.New("unknown nat method " + method)
+}
+
+// PortMapping - port mapping kept alive through NAT manager until closed
+type PortMapping struct {
+	Manager NATManager
+	Port    int
+	Name    string
+
+	quit      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// MapPort - map port through NAT manager, renewing mapping at half its lifetime until closed
+func MapPort(manager NATManager, port int, name string) (*PortMapping, error) {
+	err := manager.AddMapping(port, name, MapLifetime)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := &PortMapping{Manager: manager, Port: port, Name: name, quit: make(chan struct{}), done: make(chan struct{})}
+
+	go mapping.renew()
+
+	return mapping, nil
+}
+
+// Close - stop renewing mapping & remove it from gateway
+func (mapping *PortMapping) Close() {
+	if mapping == nil {
+		return
+	}
+
+	mapping.closeOnce.Do(func() {
+		close(mapping.quit)
+	})
+
+	<-mapping.done
+}
+
+func (mapping *PortMapping) renew() {
+	defer close(mapping.done)
+
+	ticker := time.NewTicker(MapLifetime / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := mapping.Manager.AddMapping(mapping.Port, mapping.Name, MapLifetime)
+
+			if err != nil {
+				common.ThrowWarning("failed to renew port mapping via " + mapping.Manager.String() + ": " + err.Error())
+			}
+		case <-mapping.quit:
+			err := mapping.Manager.DeleteMapping(mapping.Port)
+
+			if err != nil {
+				common.ThrowWarning("failed to remove port mapping via " + mapping.Manager.String() + ": " + err.Error())
+			}
+			return
+		}
+	}
+}
+
+// PrepareForConnection - map node port through NAT manager, keeping mapping alive until
+// DisableConnections is called; node's external address is recorded if not yet known
+func PrepareForConnection(manager NATManager, db *discovery.NodeDatabase) (*PortMapping, error) {
+	port, err := strconv.Atoi(discovery.DefaultPort)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mapping, err := MapPort(manager, port, "resourceforwarding")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if ip, err := manager.ExternalIP(); err == nil {
+		common.ThrowSuccess("current node external ip: " + ip.String())
+
+		if db != nil && db.GetSelfAddr() == "" {
+			db.SetSelfAddr(ip.String())
+		}
+	}
+
+	return mapping, nil
+}
+
+// DisableConnections - remove port mapping made by PrepareForConnection
+func DisableConnections(mapping *PortMapping) {
+	mapping.Close()
+}
+
+// GetExtIPAddr - retrieve the external IP address of the current machine via NAT manager
+func GetExtIPAddr(manager NATManager) (string, error) {
+	if manager == nil {
+		return "", ErrNoNAT
+	}
+
+	ip, err := manager.ExternalIP()
+
+	if err != nil {
+		return "", err
+	}
+
+	return ip.String(), nil
+}
@@ -0,0 +1,199 @@
+package networking
+
+import (
+	"encoding/binary"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeGateway - NAT-PMP or PCP-only gateway serving on loopback
+type fakeGateway struct {
+	conn  *net.UDPConn
+	pcp   bool
+	extIP net.IP
+
+	mu       sync.Mutex
+	mappings map[string]uint32 // Lifetime keyed by protocol & port
+}
+
+func newFakeGateway(t *testing.T, pcp bool) *fakeGateway {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gw := &fakeGateway{conn: conn, pcp: pcp, extIP: net.IPv4(198, 51, 100, 7), mappings: make(map[string]uint32)}
+
+	go gw.serve()
+
+	return gw
+}
+
+func (gw *fakeGateway) client() *PMP {
+	pmp := NewPMP(net.IPv4(127, 0, 0, 1))
+	pmp.Gateway = gw.conn.LocalAddr().(*net.UDPAddr)
+	return pmp
+}
+
+func (gw *fakeGateway) serve() {
+	buf := make([]byte, 1100)
+
+	for {
+		n, addr, err := gw.conn.ReadFromUDP(buf)
+
+		if err != nil {
+			return
+		}
+
+		if resp := gw.handle(buf[:n]); resp != nil {
+			gw.conn.WriteToUDP(resp, addr)
+		}
+	}
+}
+
+func (gw *fakeGateway) handle(req []byte) []byte {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+
+	if req[0] == pcpVersion {
+		if !gw.pcp {
+			return []byte{pmpVersion, pmpOpResponse + req[1], 0, pmpResultUnsupportedVersion, 0, 0, 0, 0}
+		}
+
+		resp := make([]byte, 60)
+		resp[0] = pcpVersion
+		resp[1] = pmpOpResponse | req[1]
+		copy(resp[4:8], req[4:8])
+		copy(resp[24:60], req[24:60])
+		copy(resp[44:60], gw.extIP.To16())
+
+		gw.record(strconv.Itoa(int(req[36])), binary.BigEndian.Uint16(req[40:42]), binary.BigEndian.Uint32(req[4:8]))
+
+		return resp
+	}
+
+	if gw.pcp {
+		return []byte{pcpVersion, pmpOpResponse | req[1], 0, pmpResultUnsupportedVersion, 0, 0, 0, 0}
+	}
+
+	if req[1] == pmpOpExternalIP {
+		return append([]byte{pmpVersion, pmpOpResponse, 0, 0, 0, 0, 0, 1}, gw.extIP.To4()...)
+	}
+
+	proto := strconv.Itoa(pcpProtoTCP)
+
+	if req[1] == pmpOpMapUDP {
+		proto = strconv.Itoa(pcpProtoUDP)
+	}
+
+	gw.record(proto, binary.BigEndian.Uint16(req[4:6]), binary.BigEndian.Uint32(req[8:12]))
+
+	return append([]byte{pmpVersion, pmpOpResponse + req[1], 0, 0, 0, 0, 0, 1}, req[4:12]...)
+}
+
+func (gw *fakeGateway) record(proto string, port uint16, lifetime uint32) {
+	key := proto + ":" + strconv.Itoa(int(port))
+
+	if lifetime == 0 {
+		delete(gw.mappings, key)
+	} else {
+		gw.mappings[key] = lifetime
+	}
+}
+
+func (gw *fakeGateway) count() int {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+
+	return len(gw.mappings)
+}
+
+func TestNATPMPMapping(t *testing.T) {
+	for _, pcp := range []bool{false, true} {
+		gw := newFakeGateway(t, pcp)
+		pmp := gw.client()
+
+		if _, err := pmp.ExternalIP(); pcp && err != ErrExternalIPUnknown {
+			t.Errorf("pcp gateway reported external address before mapping (%v)", err)
+		}
+
+		err := pmp.AddMapping(3000, "test", time.Hour)
+
+		if err != nil {
+			t.Fatalf("mapping failed (pcp %v): %s", pcp, err.Error())
+		}
+
+		if gw.count() != 2 {
+			t.Errorf("expected tcp & udp mappings, got %d", gw.count())
+		}
+
+		ip, err := pmp.ExternalIP()
+
+		if err != nil || !ip.Equal(gw.extIP) {
+			t.Errorf("unexpected external address %v (%v)", ip, err)
+		}
+
+		if pmp.legacy == pcp {
+			t.Errorf("protocol version not detected (pcp %v)", pcp)
+		}
+
+		err = pmp.DeleteMapping(3000)
+
+		if err != nil || gw.count() != 0 {
+			t.Errorf("mappings not removed (%v)", err)
+		}
+
+		gw.conn.Close()
+	}
+}
+
+func TestPortMappingRenewal(t *testing.T) {
+	gw := newFakeGateway(t, false)
+	defer gw.conn.Close()
+
+	lifetime := MapLifetime
+	MapLifetime = 20 * time.Millisecond
+	defer func() { MapLifetime = lifetime }()
+
+	mapping, err := MapPort(gw.client(), 3000, "test")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	if gw.count() != 2 {
+		t.Errorf("mapping not held while renewing")
+	}
+
+	mapping.Close()
+
+	if gw.count() != 0 {
+		t.Errorf("mapping not removed on close")
+	}
+}
+
+func TestParseNAT(t *testing.T) {
+	manager, err := ParseNAT("extip:203.0.113.9")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ip, _ := manager.ExternalIP(); ip.String() != "203.0.113.9" {
+		t.Errorf("unexpected static address %v", ip)
+	}
+
+	if manager, err := ParseNAT("none"); manager != nil || err != nil {
+		t.Errorf("expected no nat manager")
+	}
+
+	if _, err := ParseNAT("bogus"); err == nil {
+		t.Errorf("unknown nat method accepted")
+	}
+}
@@ -0,0 +1,320 @@
+package networking
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	pmpPort = 5351
+
+	pmpVersion = 0 // NAT-PMP (RFC 6886)
+	pcpVersion = 2 // PCP (RFC 6887)
+
+	pmpOpExternalIP = 0
+	pmpOpMapUDP     = 1
+	pmpOpMapTCP     = 2
+	pcpOpMap        = 1
+	pmpOpResponse   = 128
+
+	pmpResultSuccess            = 0
+	pmpResultUnsupportedVersion = 1
+
+	pcpProtoTCP = 6
+	pcpProtoUDP = 17
+
+	pmpRetries        = 4
+	pmpInitialTimeout = 250 * time.Millisecond
+)
+
+var (
+	// ErrExternalIPUnknown - returned by PCP-only gateways before any port is mapped; PCP
+	// reports external address only in mapping responses
+	ErrExternalIPUnknown = errors.New("external address not known until port is mapped")
+
+	errPMPTimeout = errors.New("gateway did not respond")
+
+	errUnsupportedVersion = errors.New("gateway does not support protocol version")
+)
+
+// PMP - NAT manager speaking PCP, falling back to NAT-PMP on gateways without PCP support
+type PMP struct {
+	Gateway *net.UDPAddr
+
+	mu     sync.Mutex
+	legacy bool     // Gateway only speaks NAT-PMP
+	nonce  [12]byte // PCP mapping nonce; identical for every mapping made by client
+	extIP  net.IP   // External address from latest PCP mapping
+}
+
+// NewPMP - return PCP/NAT-PMP manager for gateway at specified address
+func NewPMP(gateway net.IP) *PMP {
+	pmp := &PMP{Gateway: &net.UDPAddr{IP: gateway, Port: pmpPort}}
+	rand.Read(pmp.nonce[:])
+	return pmp
+}
+
+func (pmp *PMP) String() string {
+	return "pmp:" + pmp.Gateway.IP.String()
+}
+
+// ExternalIP - external address of gateway
+func (pmp *PMP) ExternalIP() (net.IP, error) {
+	resp, err := pmp.call([]byte{pmpVersion, pmpOpExternalIP}, 12)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp[0] != pmpVersion {
+		// PCP-only gateway; address is learned from mappings
+		pmp.mu.Lock()
+		defer pmp.mu.Unlock()
+
+		if pmp.extIP == nil {
+			return nil, ErrExternalIPUnknown
+		}
+		return pmp.extIP, nil
+	}
+
+	err = pmpResult(resp, pmpOpExternalIP, binary.BigEndian.Uint16(resp[2:4]))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return net.IPv4(resp[8], resp[9], resp[10], resp[11]), nil
+}
+
+// AddMapping - map TCP & UDP port on gateway to same port on current machine for specified lifetime
+func (pmp *PMP) AddMapping(port int, name string, lifetime time.Duration) error {
+	err := pmp.mapPort(pcpProtoTCP, port, lifetime)
+
+	if err != nil {
+		return err
+	}
+
+	return pmp.mapPort(pcpProtoUDP, port, lifetime)
+}
+
+// DeleteMapping - remove TCP & UDP mappings of port from gateway
+func (pmp *PMP) DeleteMapping(port int) error {
+	tcpErr := pmp.mapPort(pcpProtoTCP, port, 0)
+	udpErr := pmp.mapPort(pcpProtoUDP, port, 0)
+
+	if tcpErr != nil {
+		return tcpErr
+	}
+	return udpErr
+}
+
+// mapPort - add (or, with zero lifetime, delete) single mapping, using PCP unless gateway only speaks NAT-PMP
+func (pmp *PMP) mapPort(proto byte, port int, lifetime time.Duration) error {
+	pmp.mu.Lock()
+	legacy := pmp.legacy
+	pmp.mu.Unlock()
+
+	if !legacy {
+		err := pmp.mapPCP(proto, port, lifetime)
+
+		if err != errUnsupportedVersion {
+			return err
+		}
+
+		pmp.mu.Lock()
+		pmp.legacy = true
+		pmp.mu.Unlock()
+	}
+
+	return pmp.mapNATPMP(proto, port, lifetime)
+}
+
+func (pmp *PMP) mapPCP(proto byte, port int, lifetime time.Duration) error {
+	client, err := localAddrTo(pmp.Gateway)
+
+	if err != nil {
+		return err
+	}
+
+	req := make([]byte, 60)
+	req[0] = pcpVersion
+	req[1] = pcpOpMap
+	binary.BigEndian.PutUint32(req[4:8], lifetimeSeconds(lifetime))
+	copy(req[8:24], client.To16())
+	copy(req[24:36], pmp.nonce[:])
+	req[36] = proto
+	binary.BigEndian.PutUint16(req[40:42], uint16(port))
+	binary.BigEndian.PutUint16(req[42:44], uint16(port))
+
+	if lifetime > 0 {
+		copy(req[44:60], net.IPv4zero.To16()) // No preferred external address
+	}
+
+	resp, err := pmp.call(req, 4)
+
+	if err != nil {
+		return err
+	}
+
+	if resp[0] != pcpVersion || resp[3] == pmpResultUnsupportedVersion {
+		return errUnsupportedVersion
+	}
+
+	if len(resp) < 60 || resp[1] != pmpOpResponse|pcpOpMap || string(resp[24:36]) != string(pmp.nonce[:]) {
+		return errors.New("invalid pcp response")
+	}
+
+	if resp[3] != pmpResultSuccess {
+		return errors.New("pcp mapping failed with result code " + strconv.Itoa(int(resp[3])))
+	}
+
+	if lifetime > 0 {
+		pmp.mu.Lock()
+		pmp.extIP = net.IP(append([]byte(nil), resp[44:60]...))
+		pmp.mu.Unlock()
+	}
+
+	return nil
+}
+
+func (pmp *PMP) mapNATPMP(proto byte, port int, lifetime time.Duration) error {
+	op := byte(pmpOpMapTCP)
+
+	if proto == pcpProtoUDP {
+		op = pmpOpMapUDP
+	}
+
+	req := make([]byte, 12)
+	req[0] = pmpVersion
+	req[1] = op
+	binary.BigEndian.PutUint16(req[4:6], uint16(port))
+
+	if lifetime > 0 {
+		binary.BigEndian.PutUint16(req[6:8], uint16(port))
+	}
+
+	binary.BigEndian.PutUint32(req[8:12], lifetimeSeconds(lifetime))
+
+	resp, err := pmp.call(req, 16)
+
+	if err != nil {
+		return err
+	}
+
+	return pmpResult(resp, op, binary.BigEndian.Uint16(resp[2:4]))
+}
+
+// call - send request to gateway, retransmitting with doubling timeout until response of at least minLen bytes arrives
+func (pmp *PMP) call(req []byte, minLen int) ([]byte, error) {
+	conn, err := net.DialUDP("udp", nil, pmp.Gateway)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	buf := make([]byte, 1100)
+	wait := pmpInitialTimeout
+
+	for try := 0; try < pmpRetries; try++ {
+		_, err = conn.Write(req)
+
+		if err != nil {
+			return nil, err
+		}
+
+		conn.SetReadDeadline(time.Now().Add(wait))
+
+		n, err := conn.Read(buf)
+
+		if err == nil {
+			if n < 4 || (buf[0] == req[0] && n < minLen) {
+				return nil, errors.New("truncated gateway response")
+			}
+			return buf[:n], nil
+		}
+
+		if netErr, ok := err.(net.Error); !ok || !netErr.Timeout() {
+			return nil, err
+		}
+
+		wait *= 2
+	}
+
+	return nil, errPMPTimeout
+}
+
+func pmpResult(resp []byte, op byte, result uint16) error {
+	if resp[1] != pmpOpResponse+op {
+		return errors.New("unexpected nat-pmp response opcode")
+	}
+
+	if result != pmpResultSuccess {
+		return errors.New("nat-pmp request failed with result code " + strconv.Itoa(int(result)))
+	}
+
+	return nil
+}
+
+// lifetimeSeconds - mapping lifetime in whole seconds; positive lifetimes are at least one
+// second, as zero lifetime deletes mapping
+func lifetimeSeconds(lifetime time.Duration) uint32 {
+	if lifetime > 0 && lifetime < time.Second {
+		return 1
+	}
+	return uint32(lifetime / time.Second)
+}
+
+// localAddrTo - local address used to reach specified address
+func localAddrTo(addr *net.UDPAddr) (net.IP, error) {
+	conn, err := net.DialUDP("udp", nil, addr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	return conn.LocalAddr().(*net.UDPAddr).IP, nil
+}
+
+// DefaultGateway - address of default IPv4 gateway, read from kernel routing table (Linux only)
+func DefaultGateway() (net.IP, error) {
+	file, err := os.Open("/proc/net/route")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) < 3 || fields[1] != "00000000" {
+			continue
+		}
+
+		raw, err := hex.DecodeString(fields[2])
+
+		if err != nil || len(raw) != 4 {
+			continue
+		}
+
+		return net.IPv4(raw[3], raw[2], raw[1], raw[0]), nil // Stored little-endian
+	}
+
+	return nil, errors.New("no default gateway found")
+}
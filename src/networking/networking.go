@@ -8,20 +8,18 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net"
-	"net/http"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/mitsukomegumi/indo-go/src/common"
 	"github.com/mitsukomegumi/indo-go/src/consensus"
+	"github.com/mitsukomegumi/indo-go/src/core/mempool"
 	"github.com/mitsukomegumi/indo-go/src/core/types"
 	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
-
-	upnp "github.com/nebulouslabs/go-upnp"
 )
 
 const (
@@ -29,100 +27,43 @@ const (
 	rDelay  = 2 * time.Second
 )
 
-func forward(GatewayDevice *upnp.IGD) {
-	// discover external IP
-	ip, err := GatewayDevice.ExternalIP()
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println("current node external ip:", ip)
-
-	// forward a port
-	err = GatewayDevice.Forward(3000, "resourceforwarding")
-	if err != nil {
-		log.Fatal(err)
-	}
+// Relay - push localized or received transaction to peers, checking admission against local chain
+func Relay(Tx *types.Transaction, Db *discovery.NodeDatabase) error {
+	return RelayWithChain(Tx, types.ReadChainFromMemory(common.GetCurrentDir()), Db)
 }
 
-func removeMapping(GatewayDevice *upnp.IGD) {
-	// discover external IP
-	ip, err := GatewayDevice.ExternalIP()
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println("current node external ip:", ip)
-
-	// remove port mappings
-	err = GatewayDevice.Clear(3000)
-	if err != nil {
-		log.Fatal(err)
+// RelayWithChain - push transaction to peers, checking admission against specified local chain & mempool
+// rather than the network; transactions not yet in chain are held in chain's mempool
+func RelayWithChain(Tx *types.Transaction, Ch *types.Chain, Db *discovery.NodeDatabase) error {
+	if seenTxs.has(Tx.Hash()) {
+		return ErrAlreadyRelayed
 	}
-}
 
-// PrepareForConnection - forward all necessary ports to decrease redundant speed limitations
-func PrepareForConnection(GatewayDevice *upnp.IGD, db *discovery.NodeDatabase) {
-	forward(GatewayDevice)
-}
-
-// DisableConnections - remove all necessary port mappings
-func DisableConnections(GatewayDevice *upnp.IGD, db *discovery.NodeDatabase) {
-	removeMapping(GatewayDevice)
-}
+	var chainID common.Identifier
 
-// GetExtIPAddr - retrieve the external IP address of the current machine
-func GetExtIPAddr() string {
-	// connect to router
-	d, err := upnp.Discover()
-	if err != nil {
-		log.Fatal(err)
+	if Ch != nil {
+		chainID = Ch.Identifier
 	}
 
-	// discover external IP
-	ip, err := d.ExternalIP()
-	if err != nil {
-		log.Fatal(err)
-	}
-	return ip
-}
+	common.ThrowWarning("verifying tx on local chain")
 
-// GetExtIPAddrNoUpNP - retrieve the external IP address of the current machine w/o upnp
-func GetExtIPAddrNoUpNP() (string, error) {
-	ip := make([]byte, 100)
-	resp, err := http.Get("http://checkip.amazonaws.com/")
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-	_, err = resp.Body.Read(ip)
+	err := Admission.CheckAdmission(Tx, Ch)
 
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	return string(ip[:len(ip)]), nil
-}
-
-// Relay - push localized or received transaction to further node
-func Relay(Tx *types.Transaction, Db *discovery.NodeDatabase) error {
-	if !reflect.ValueOf(Tx.InitialWitness).IsNil() {
-		common.ThrowWarning("verifying tx on current chain")
-		fChain, err := FetchChain(Db)
+	if Ch == nil || !Ch.HasTransaction(Tx.Hash()) {
+		err = MempoolFor(chainID).Add(Tx)
 
-		if err != nil {
+		if err != nil && err != mempool.ErrDuplicate {
 			return err
 		}
+	}
 
-		if fChain.Transactions[len(fChain.Transactions)-1].InitialWitness.WitnessTime.Before(Tx.InitialWitness.WitnessTime) {
-			common.ThrowSuccess("tx passed checks; relaying")
-			txBytes := new(bytes.Buffer)
-			json.NewEncoder(txBytes).Encode(Tx)
-			newConnection(Db.SelfAddr, Db.FindNode(), "relay", txBytes.Bytes()).attempt()
+	common.ThrowSuccess("tx passed checks; relaying")
 
-			return nil
-		}
-		return errors.New("transaction behind latest chain; fetch latest chain")
-	}
-	return errors.New("operation not permitted; transaction not witnessed")
+	return gossipTx(Tx, chainID, Db, Gossip.MaxHops, "")
 }
 
 // RelayChain - push localized or received chain to further node
@@ -134,32 +75,60 @@ func RelayChain(Ch *types.Chain, Db *discovery.NodeDatabase) error {
 		return err
 	}
 
-	newConnection(Db.SelfAddr, Db.FindNode(), "fullchain", chBytes.Bytes()).attempt()
+	conn := newConnection(Db.SelfAddr, Db.FindNode(), "fullchain", chBytes.Bytes())
+	conn.ChainID = Ch.Identifier
+
+	conn.attempt(Db)
 
 	return nil
 }
 
-// HostChain - host localized chain to forwarded port
+// HostWitness - witness hosting node witnesses relayed transactions into hosted chains with; relayed
+// transactions are only held in mempool while unset
+var HostWitness *types.Witness
+
+// HostChain - host localized chain to forwarded port; connections scoped to other chains in
+// Chains are served from the same port
 func HostChain(Ch *types.Chain, Db *discovery.NodeDatabase, Loop bool) {
 	if reflect.ValueOf(Ch.NodeDb).IsNil() {
 		*Ch = types.Chain{ParentContract: Ch.ParentContract, Identifier: Ch.Identifier, NodeDb: Db, Transactions: Ch.Transactions, Version: Ch.Version}
 	}
+	Chains.Register(Ch)
 	common.ThrowWarning("attempting to host chain with address " + Ch.NodeDb.SelfAddr)
 	if Loop == true {
 		for {
-			chBytes := new(bytes.Buffer)
-			json.NewEncoder(chBytes).Encode(Ch)
-			newConnection(Db.SelfAddr, "", "statichostfullchain", chBytes.Bytes()).start(Ch)
+			hostChainConnection(Db.SelfAddr, Ch).start(Ch)
 		}
 	} else {
-		chBytes := new(bytes.Buffer)
-		json.NewEncoder(chBytes).Encode(Ch)
-		newConnection(Db.SelfAddr, "", "statichostfullchain", chBytes.Bytes()).start(Ch)
+		hostChainConnection(Db.SelfAddr, Ch).start(Ch)
 	}
 }
 
+// hostChainConnection - statichostfullchain connection carrying specified chain
+func hostChainConnection(selfAddr string, Ch *types.Chain) *Connection {
+	chBytes := new(bytes.Buffer)
+	json.NewEncoder(chBytes).Encode(Ch)
+
+	conn := newConnection(selfAddr, "", "statichostfullchain", chBytes.Bytes())
+	conn.ChainID = Ch.Identifier
+
+	return conn
+}
+
 // ListenRelay - listen for transaction relays, relay to full node or host
 func ListenRelay() *types.Transaction {
+	tempCon := listenRelay(nil)
+
+	if tempCon == nil {
+		return nil
+	}
+
+	return types.DecodeTxFromBytes(tempCon.Data)
+}
+
+// listenRelay - listen for single transaction relay from peer not banned in specified database,
+// returning received connection
+func listenRelay(Db *discovery.NodeDatabase) *Connection {
 	tempCon := Connection{}
 
 	ln, err := net.Listen("tcp", ":3000")
@@ -169,32 +138,55 @@ func ListenRelay() *types.Transaction {
 		panic(err)
 	}
 
-	conn, err := ln.Accept()
-	conn.SetDeadline(time.Now().Add(timeout))
+	conn, err := accept(ln, Db)
 
 	if err != nil {
 		fmt.Println(err)
 		panic(err)
 	}
 
+	conn.SetDeadline(time.Now().Add(timeout))
+
 	var message bytes.Buffer
 
-	io.Copy(&message, conn)
+	_, err = io.Copy(&message, io.LimitReader(conn, MaxMessageSize+1))
+
+	if err == nil && int64(message.Len()) > MaxMessageSize {
+		err = ErrMessageTooLarge
+	}
 
 	if err != nil {
 		common.ThrowWarning("conn err: " + err.Error())
+
+		if violation, found := readViolation(err); found {
+			misbehave(Db, conn, violation)
+		}
+
+		conn.Close()
+		ln.Close()
+
+		return nil
 	}
 
+	err = tempCon.ResolveData(message.Bytes())
+
 	if err != nil {
-		panic(err)
+		common.ThrowWarning("error while resolving connection data: " + err.Error())
+		misbehave(Db, conn, discovery.ViolationUndecodable)
+
+		conn.Close()
+		ln.Close()
+
+		return nil
 	}
 
-	tempCon.ResolveData(message.Bytes())
+	tempCon.PeerID = conn.RemoteID
+	tempCon.PeerAddr = remoteHost(conn)
 
 	if tempCon.Type == "relay" {
 		conn.Close()
 		ln.Close()
-		return types.DecodeTxFromBytes(tempCon.Data)
+		return &tempCon
 	}
 
 	common.ThrowWarning("chain relay found; wanted transaction")
@@ -205,26 +197,30 @@ func ListenRelay() *types.Transaction {
 }
 
 // ListenChain - listen for chain relays, relay to full node or host
-func ListenChain() *types.Chain {
+func ListenChain() (*types.Chain, error) {
 	tempCon := Connection{}
 
 	ln, err := net.Listen("tcp", ":3000")
 
 	if err != nil {
-		fmt.Println(err)
-		panic(err)
+		return nil, err
 	}
-	conn, err := ln.Accept()
-	conn.SetDeadline(time.Now().Add(timeout))
+
+	defer ln.Close()
+
+	conn, err := accept(ln, nil)
 
 	if err != nil {
-		fmt.Println(err)
-		panic(err)
+		return nil, err
 	}
 
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(timeout))
+
 	var message bytes.Buffer
 
-	io.Copy(&message, conn)
+	_, err = io.Copy(&message, conn)
 
 	if err != nil {
 		common.ThrowWarning("conn err: " + err.Error())
@@ -232,41 +228,33 @@ func ListenChain() *types.Chain {
 
 	tempCon.ResolveData(message.Bytes())
 
-	if tempCon.Type == "fullchain" {
-		conn.Close()
-		ln.Close()
-
-		decodedChain, err := types.DecodeChainFromBytes(tempCon.Data)
-
-		if err != nil {
-			panic(err)
-		}
-
-		return decodedChain
+	if tempCon.Type != "fullchain" {
+		return nil, errors.New("transaction relay found; wanted chain")
 	}
 
-	common.ThrowWarning("transaction relay found; wanted chain")
-	conn.Close()
-	ln.Close()
-
-	return nil
+	return types.DecodeChainFromBytes(tempCon.Data)
 }
 
 // FetchChain - get current chain from best node; get from nodes with statichostfullchain connection type
 func FetchChain(Db *discovery.NodeDatabase) (*types.Chain, error) {
+	return FetchChainWithIdentifier(Db, nil)
+}
+
+// FetchChainWithIdentifier - get current copy of chain with specified identifier from best node
+func FetchChainWithIdentifier(Db *discovery.NodeDatabase, id common.Identifier) (*types.Chain, error) {
 	Node := Db.FindNode()
 
 	hash := crypto.SHA256.New()
 
-	tempCon := Connection{InitNodeAddr: Db.SelfAddr, DestNodeAddr: Node, Type: "fetchchain", Time: time.Now().UTC(), TimeHash: &types.Hash{}, Hash: &types.Hash{}}
+	tempCon := Connection{InitNodeAddr: Db.SelfAddr, DestNodeAddr: Node, Type: "fetchchain", ChainID: id, Time: time.Now().UTC(), TimeHash: &common.Hash{}, Hash: &common.Hash{}}
 
 	timeByteArray := hash.Sum([]byte(fmt.Sprintf("%v", tempCon.Time)))
 
-	*tempCon.TimeHash = types.BytesToHash(timeByteArray)
+	*tempCon.TimeHash = common.BytesToHash(timeByteArray)
 
 	bArray := hash.Sum([]byte(fmt.Sprintf("%v", tempCon)))
 
-	*tempCon.Hash = types.BytesToHash(bArray)
+	*tempCon.Hash = common.BytesToHash(bArray)
 
 	fmt.Println("connection " + tempCon.Type)
 
@@ -274,20 +262,17 @@ func FetchChain(Db *discovery.NodeDatabase) (*types.Chain, error) {
 	connBytes := new(bytes.Buffer)
 	json.NewEncoder(connBytes).Encode(tempCon)
 
-	connec, err := net.Dial("tcp", Node+":3000") // Connect to peer addr
+	common.ThrowWarning("attempting to connect to node " + discovery.HostPort(Node, discovery.DefaultPort))
 
-	common.ThrowWarning("attempting to connect to node " + Node + ":3000")
+	connec, err := dial(Node, Db.PeerID(Node), Db) // Connect to peer addr
 
 	if err != nil {
-		defer func() {
-			fmt.Println(err)
-		}()
-		connec.Close()
-
+		fmt.Println(err)
 		return nil, err
 	}
 
 	connec.Write(connBytes.Bytes())
+	connec.CloseWrite()
 	fmt.Printf("\nwrote connection meta: %s", connBytes.String())
 
 	/*
@@ -309,25 +294,31 @@ func FetchChain(Db *discovery.NodeDatabase) (*types.Chain, error) {
 
 	decomp, err := common.DecompressBytes(message.Bytes())
 
+	if err != nil {
+		connec.Close()
+		misbehave(Db, connec, discovery.ViolationInvalidChain)
+		return nil, err
+	}
+
 	tempCon.ResolveData(decomp)
 
 	if tempCon.Type == "statichostfullchain" {
 		connec.Close()
 
-		rCh, err := types.DecodeChainFromBytes(tempCon.Data)
+		decodedChain, err := types.DecodeChainFromBytes(tempCon.Data)
 
-		if err != nil {
-			panic(err)
+		if err == nil && !decodedChain.Identifier.Equal(id) {
+			err = errors.New("host served chain " + decodedChain.Identifier.String() + "; requested " + id.String())
 		}
 
-		*Db = *rCh.NodeDb
-
-		decodedChain, err := types.DecodeChainFromBytes(tempCon.Data)
-
 		if err != nil {
+			misbehave(Db, connec, discovery.ViolationInvalidChain)
 			return nil, err
 		}
 
+		Db.Merge(decodedChain.NodeDb) // Learn peers known by host, keeping local identity
+		decodedChain.NodeDb = Db
+
 		return decodedChain, nil
 	}
 
@@ -336,32 +327,132 @@ func FetchChain(Db *discovery.NodeDatabase) (*types.Chain, error) {
 	return nil, errors.New("chain not found")
 }
 
-// ListenRelayWithAdd - listen for transaction relays, add to local chain
+// ListenRelayWithAdd - listen for transaction relays, add to mempool & forward to further peers;
+// pending transactions are witnessed into local chain unless chain is syncing
 func ListenRelayWithAdd(Ch *types.Chain, Wit *types.Witness, Db *discovery.NodeDatabase) {
-	tx := ListenRelay()
-	consensus.WitnessTransaction(tx, Wit)
-	Ch.AddTransaction(tx)
+	conn := listenRelay(Db)
+
+	if conn == nil {
+		return
+	}
+
+	tx, err := decodeTx(conn.Data)
+
+	if err != nil {
+		common.ThrowWarning("error while decoding transaction: " + err.Error())
+		Db.Misbehave(conn.PeerAddr, conn.PeerID, discovery.ViolationUndecodable)
+		return
+	}
+
+	Ch, err = chainFor(conn, Ch)
+
+	if err != nil {
+		common.ThrowWarning("dropping transaction for chain " + conn.ChainID.String() + ": " + err.Error())
+		return
+	}
+
+	pool := MempoolFor(Ch.Identifier)
+
+	if !seenTxs.markSeen(tx.Hash()) {
+		common.ThrowWarning("transaction already seen; dropping")
+		return
+	}
+
+	err = admitReceived(tx, Ch)
+
+	if err != nil {
+		common.ThrowWarning("transaction not admitted: " + err.Error())
+
+		if txViolation(err) {
+			Db.Misbehave(conn.PeerAddr, conn.PeerID, discovery.ViolationInvalidTransaction)
+		}
+		return
+	}
+
+	err = pool.Add(tx)
+
+	if err != nil {
+		common.ThrowWarning("transaction rejected by mempool: " + err.Error())
+		return
+	}
+
+	forwardRelay(conn, tx, Db)
+
+	witnessPending(Ch, Wit)
+}
+
+// witnessPending - witness transactions pending in chain's mempool into chain & persist chain; transactions
+// are held in mempool while chain is syncing
+func witnessPending(Ch *types.Chain, Wit *types.Witness) {
+	if IsSyncing(Ch.Identifier) {
+		common.ThrowWarning("chain syncing; holding transaction in mempool")
+		return
+	}
+
+	pool := MempoolFor(Ch.Identifier)
+
+	consensus.WitnessPending(pool, Ch, Wit, pool.Len())
+	checkpointIfDue(Ch)
 	Ch.WriteChainToMemory(common.GetCurrentDir())
-	Relay(tx, Db)
+}
+
+// checkpointIfDue - sign & add checkpoint to chain with node's identity key once checkpoint is due,
+// followed by state snapshot once snapshot is due
+func checkpointIfDue(Ch *types.Chain) {
+	key, err := GetNodeKey()
+
+	if err != nil {
+		common.ThrowWarning("not checkpointing; node key unavailable: " + err.Error())
+		return
+	}
+
+	h, err := consensus.CheckpointIfDue(Ch, key)
+
+	if err != nil {
+		common.ThrowWarning("checkpoint failed: " + err.Error())
+	} else if h != nil {
+		common.ThrowSuccess("added checkpoint " + strconv.Itoa(h.Number) + " covering chain versions " + strconv.Itoa(h.FromVersion) + "-" + strconv.Itoa(h.ToVersion))
+	}
+
+	s, err := consensus.SnapshotIfDue(Ch, key)
+
+	if err != nil {
+		common.ThrowWarning("snapshot failed: " + err.Error())
+	} else if s != nil {
+		common.ThrowSuccess("added snapshot at checkpoint " + strconv.Itoa(s.Checkpoint) + " covering " + strconv.Itoa(len(s.Accounts)) + " accounts")
+	}
 }
 
 // ListenChainWithAdd - listen for chain relays, set local chain to result
-func ListenChainWithAdd(Ch *types.Chain, Db *discovery.NodeDatabase) {
-	*Ch = *ListenChain()
+func ListenChainWithAdd(Ch *types.Chain, Db *discovery.NodeDatabase) error {
+	chain, err := ListenChain()
+
+	if err != nil {
+		return err
+	}
+
+	next, err := applyFullChain(Ch, chain)
+
+	if err != nil {
+		return err
+	}
+
+	*Ch = *next
 	Ch.WriteChainToMemory(common.GetCurrentDir())
 	RelayChain(Ch, Db)
+
+	return nil
 }
 
-// FetchChainWithAdd - fetch chain, set local chain to result
+// FetchChainWithAdd - fetch chain with identifier of local chain, set local chain to result
 func FetchChainWithAdd(Ch *types.Chain, Db *discovery.NodeDatabase) error {
-	fChain, err := FetchChain(Db)
+	fChain, err := FetchChainWithIdentifier(Db, Ch.Identifier)
 
 	if err != nil {
 		return err
 	}
 
 	*Ch = *fChain
-	*Ch.NodeDb = *fChain.NodeDb
 	Ch.WriteChainToMemory(common.GetCurrentDir())
 	Ch.NodeDb.WriteDbToMemory(common.GetCurrentDir())
 
@@ -374,14 +465,14 @@ func handleReceivedBytes(b []byte) *Connection {
 	return &tempConn
 }
 
-func (conn *Connection) attempt() error {
+func (conn *Connection) attempt(Db *discovery.NodeDatabase) error {
 	conn.AddEvent("started")
 	connBytes := new(bytes.Buffer)
 	json.NewEncoder(connBytes).Encode(conn)
 
-	common.ThrowWarning("\nattempting to dial address: " + conn.DestNodeAddr + ":3000")
+	common.ThrowWarning("\nattempting to dial address: " + discovery.HostPort(conn.DestNodeAddr, discovery.DefaultPort))
 
-	connec, err := net.Dial("tcp", conn.DestNodeAddr+":3000") // Connect to peer addr
+	connec, err := dial(conn.DestNodeAddr, Db.PeerID(conn.DestNodeAddr), Db) // Connect to peer addr
 
 	if err != nil {
 		return err
@@ -389,6 +480,7 @@ func (conn *Connection) attempt() error {
 
 	//connec.SetDeadline(time.Now().Add(timeout)) // Set timeout
 	connec.Write(connBytes.Bytes()) // Write connection meta
+	connec.CloseWrite()
 
 	finished := make(chan bool)
 
@@ -404,6 +496,89 @@ func (conn *Connection) attempt() error {
 	return nil
 }
 
+// request - send connection to destination node, returning node's response; if expected ID is
+// set, destination node must prove that ID. Malformed responses count as misbehavior in specified database.
+func (conn *Connection) request(expected discovery.NodeID, Db *discovery.NodeDatabase) (*Connection, error) {
+	conn.AddEvent("started")
+	connBytes := new(bytes.Buffer)
+	err := json.NewEncoder(connBytes).Encode(conn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	connec, err := dial(conn.DestNodeAddr, expected, Db) // Connect to peer addr
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer connec.Close()
+
+	connec.SetDeadline(time.Now().Add(timeout))
+
+	_, err = connec.Write(connBytes.Bytes()) // Write connection meta
+
+	if err != nil {
+		return nil, err
+	}
+
+	connec.CloseWrite()
+
+	var message bytes.Buffer
+
+	_, err = io.Copy(&message, io.LimitReader(connec, MaxMessageSize+1))
+
+	if err == nil && int64(message.Len()) > MaxMessageSize {
+		err = ErrMessageTooLarge
+	}
+
+	if err != nil {
+		if violation, found := readViolation(err); found {
+			misbehave(Db, connec, violation)
+		}
+		return nil, err
+	}
+
+	decomp, err := common.DecompressBytes(message.Bytes())
+
+	if err != nil {
+		misbehave(Db, connec, discovery.ViolationUndecodable)
+		return nil, err
+	}
+
+	resp := Connection{}
+	err = resp.ResolveData(decomp)
+
+	if err != nil {
+		misbehave(Db, connec, discovery.ViolationUndecodable)
+		return nil, err
+	}
+
+	resp.PeerID = connec.RemoteID
+
+	return &resp, nil
+}
+
+// respond - write specified response connection to requesting peer
+func respond(connec net.Conn, resp *Connection) error {
+	if resp == nil {
+		return errors.New("invalid response connection")
+	}
+
+	resp.AddEvent("started")
+	respBytes := new(bytes.Buffer)
+	err := json.NewEncoder(respBytes).Encode(resp)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = connec.Write(common.CompressBytes(respBytes.Bytes()))
+
+	return err
+}
+
 func (conn *Connection) start(Ch *types.Chain) {
 	ln, err := net.Listen("tcp", ":3000")
 	if err != nil {
@@ -411,7 +586,7 @@ func (conn *Connection) start(Ch *types.Chain) {
 		panic(err)       // Panic
 	}
 
-	connec, err := ln.Accept() // Accept peer connection
+	connec, err := accept(ln, Ch.NodeDb) // Accept peer connection
 
 	if err != nil {
 		fmt.Println(err)
@@ -502,7 +677,14 @@ func handleRequest(connec net.Conn, data chan []byte, conn *Connection, Ch *type
 
 	err := resolveConnection(connec, data)
 	if err != nil {
-		panic(err)
+		common.ThrowWarning("error while resolving connection: " + err.Error())
+
+		if violation, found := readViolation(err); found {
+			misbehave(Ch.NodeDb, connec, violation)
+		}
+
+		finished <- true
+		return
 	}
 
 	go finalizeResolvedConnection(data, finishedAgainBool, Ch, connBytes, connec) // call from resolveconnection routine
@@ -527,21 +709,77 @@ func finalizeResolvedConnection(data chan []byte, finished chan bool, Ch *types.
 
 	rErr := tempCon.ResolveData(<-data)
 
+	if sConn, ok := connec.(*SecureConn); ok {
+		tempCon.PeerID = sConn.RemoteID // Use proven identity rather than any asserted in data
+	}
+
 	if rErr != nil {
 		common.ThrowWarning("error while resolving connection data: " + rErr.Error())
+		misbehave(Ch.NodeDb, connec, discovery.ViolationUndecodable)
 
 		finished <- true
 	} else {
 		fmt.Println("\nConnection type: " + tempCon.Type)
 
+		target := Ch
+
+		if chainScoped(tempCon.Type) {
+			var err error
+
+			target, err = chainFor(&tempCon, Ch)
+
+			if err != nil {
+				common.ThrowWarning("ignoring " + string(tempCon.Type) + " for chain " + tempCon.ChainID.String() + ": " + err.Error())
+
+				finished <- true
+				return
+			}
+		}
+
 		if tempCon.Type == "fullchain" {
 			chain, err := types.DecodeChainFromBytes(tempCon.Data)
 
+			if err == nil && !chain.Identifier.Equal(target.Identifier) {
+				err = errors.New("chain identifier does not match connection chain id")
+			}
+
 			if err != nil {
-				panic(err)
+				common.ThrowWarning("error while decoding chain: " + err.Error())
+				misbehave(Ch.NodeDb, connec, discovery.ViolationInvalidChain)
+
+				finished <- true
+				return
+			}
+
+			next, err := applyFullChain(target, chain)
+
+			if err != nil {
+				common.ThrowWarning("rejecting relayed chain: " + err.Error())
+
+				if err != types.ErrInsufficientSignatures {
+					misbehave(Ch.NodeDb, connec, discovery.ViolationInvalidChain)
+				}
+
+				finished <- true
+				return
 			}
 
-			*Ch = *chain
+			localDb := target.NodeDb
+
+			if localDb == nil {
+				localDb = Ch.NodeDb
+			}
+
+			if next.ParentContract == nil {
+				next.ParentContract = chain.ParentContract
+			}
+
+			*target = *next
+
+			if localDb != nil {
+				localDb.Merge(chain.NodeDb) // Learn peers known by sender, keeping local identity
+				target.NodeDb = localDb
+			}
 
 			common.ThrowSuccess("found chain: ")
 
@@ -551,17 +789,49 @@ func finalizeResolvedConnection(data chan []byte, finished chan bool, Ch *types.
 			}
 			os.Stdout.Write(b)
 
-			if !reflect.ValueOf(Ch.NodeDb.NodeAddress).IsNil() {
-				common.ThrowSuccess("found node: " + Ch.NodeDb.NodeAddress[len(Ch.NodeDb.NodeAddress)-1])
-			}
+			if target.NodeDb != nil {
+				common.ThrowSuccess("known nodes: " + strconv.Itoa(target.NodeDb.Len()))
 
-			Ch.WriteChainToMemory(common.GetCurrentDir())
-			Ch.NodeDb.WriteDbToMemory(common.GetCurrentDir())
+				target.WriteChainToMemory(common.GetCurrentDir())
+			}
 
 			finished <- true
 		} else if tempCon.Type == "relay" {
-			tx := types.DecodeTxFromBytes(tempCon.Data)
-			Ch.AddTransaction(tx)
+			tx, err := decodeTx(tempCon.Data)
+
+			if err != nil {
+				common.ThrowWarning("error while decoding transaction: " + err.Error())
+				misbehave(Ch.NodeDb, connec, discovery.ViolationUndecodable)
+
+				finished <- true
+				return
+			}
+
+			if !seenTxs.markSeen(tx.Hash()) {
+				common.ThrowWarning("transaction already seen; dropping")
+
+				finished <- true
+				return
+			}
+
+			err = admitReceived(tx, target)
+
+			if txViolation(err) {
+				misbehave(Ch.NodeDb, connec, discovery.ViolationInvalidTransaction)
+			}
+
+			if err == nil {
+				err = MempoolFor(target.Identifier).Add(tx)
+			}
+
+			if err != nil {
+				common.ThrowWarning("transaction not admitted: " + err.Error())
+
+				finished <- true
+				return
+			}
+
+			go forwardRelay(&tempCon, tx, Ch.NodeDb)
 
 			common.ThrowSuccess("found transaction: ")
 
@@ -571,12 +841,22 @@ func finalizeResolvedConnection(data chan []byte, finished chan bool, Ch *types.
 			}
 			os.Stdout.Write(b)
 
-			Ch.WriteChainToMemory(common.GetCurrentDir())
+			if HostWitness != nil {
+				witnessPending(target, HostWitness)
+			}
 
 			finished <- true
 		} else if tempCon.Type == "fetchchain" {
 			fmt.Println("writing to connection")
 
+			if target != Ch {
+				resp := hostChainConnection(Ch.NodeDb.SelfAddr, target)
+				resp.AddEvent("started")
+
+				connBytes = new(bytes.Buffer)
+				json.NewEncoder(connBytes).Encode(resp)
+			}
+
 			b := common.CompressBytes(connBytes.Bytes())
 
 			_, wErr := connec.Write(b) // Write connection meta
@@ -585,13 +865,81 @@ func finalizeResolvedConnection(data chan []byte, finished chan bool, Ch *types.
 				common.ThrowWarning(wErr.Error())
 			}
 
+			finished <- true
+		} else if tempCon.Type == "ping" {
+			selfAddr := ""
+
+			if Ch.NodeDb != nil {
+				selfAddr = Ch.NodeDb.SelfAddr
+			}
+
+			err := handlePing(&tempCon, selfAddr, connec)
+
+			if err != nil {
+				common.ThrowWarning("error while answering ping: " + err.Error())
+			}
+
+			finished <- true
+		} else if tempCon.Type == "findnode" {
+			err := handleFindNode(&tempCon, Ch.NodeDb, connec)
+
+			if err != nil {
+				common.ThrowWarning("error while serving node lookup: " + err.Error())
+			}
+
+			finished <- true
+		} else if tempCon.Type == "syncrequest" {
+			err := handleSyncRequest(&tempCon, target, connec)
+
+			if err != nil {
+				common.ThrowWarning("error while serving sync request: " + err.Error())
+			}
+
+			finished <- true
+		} else if tempCon.Type == "proofrequest" {
+			err := handleProofRequest(&tempCon, target, connec)
+
+			if err != nil {
+				common.ThrowWarning("error while serving proof request: " + err.Error())
+			}
+
+			finished <- true
+		} else if tempCon.Type == "headerrequest" {
+			err := handleHeaderRequest(&tempCon, target, connec)
+
+			if err != nil {
+				common.ThrowWarning("error while serving header request: " + err.Error())
+			}
+
+			finished <- true
+		} else if tempCon.Type == "accountrequest" {
+			err := handleAccountRequest(&tempCon, target, connec)
+
+			if err != nil {
+				common.ThrowWarning("error while serving account request: " + err.Error())
+			}
+
+			finished <- true
+		} else if tempCon.Type == "snapshotrequest" {
+			err := handleSnapshotRequest(&tempCon, target, connec)
+
+			if err != nil {
+				common.ThrowWarning("error while serving snapshot request: " + err.Error())
+			}
+
+			finished <- true
+		} else {
+			common.ThrowWarning("unhandled connection type: " + string(tempCon.Type))
+
 			finished <- true
 		}
 	}
 }
 
 func resolveConnectionData(conn net.Conn, buf chan []byte) error {
-	firstLine, _, err := bufio.NewReader(conn).ReadLine()
+	limited := io.LimitReader(conn, MaxMessageSize+1)
+
+	firstLine, _, err := bufio.NewReader(limited).ReadLine()
 
 	if err != nil {
 		return err
@@ -605,10 +953,18 @@ func resolveConnectionData(conn net.Conn, buf chan []byte) error {
 			return err
 		}
 		var tmpBuffer bytes.Buffer
-		io.Copy(&tmpBuffer, conn)
+		_, err = io.Copy(&tmpBuffer, limited)
+
+		if err != nil {
+			return err
+		}
 
 		concatBuf := append(firstLine, tmpBuffer.Bytes()...)
 
+		if int64(len(concatBuf)) > MaxMessageSize {
+			return ErrMessageTooLarge
+		}
+
 		fmt.Println(string(concatBuf))
 
 		buf <- concatBuf
@@ -640,15 +996,15 @@ func newConnection(initAddr string, destAddr string, connType ConnectionType, da
 	fmt.Printf("connection init at %s\n", common.GetCurrentTime())
 	if common.StringInSlice(string(connType), ConnectionTypes) {
 		hash := crypto.SHA256.New()
-		conn := Connection{InitNodeAddr: initAddr, DestNodeAddr: destAddr, Type: connType, Data: data, Time: time.Now().UTC(), TimeHash: &types.Hash{}, Hash: &types.Hash{}}
+		conn := Connection{InitNodeAddr: initAddr, DestNodeAddr: destAddr, Type: connType, Data: data, Time: time.Now().UTC(), TimeHash: &common.Hash{}, Hash: &common.Hash{}}
 
 		timeByteArray := hash.Sum([]byte(fmt.Sprintf("%v", conn.Time)))
 
-		*conn.TimeHash = types.BytesToHash(timeByteArray)
+		*conn.TimeHash = common.BytesToHash(timeByteArray)
 
 		bArray := hash.Sum([]byte(fmt.Sprintf("%v", conn)))
 
-		*conn.Hash = types.BytesToHash(bArray)
+		*conn.Hash = common.BytesToHash(bArray)
 
 		return &conn
 	}
@@ -1,4 +1,9 @@
 package networking
 
+import "github.com/mitsukomegumi/indo-go/src/core/mempool"
+
+// Mempool - transactions received by current node, awaiting witnessing & chain inclusion
+var Mempool = mempool.NewMempool(mempool.DefaultLimit, nil)
+
 // NodeID - byte array identifying individual node
 type NodeID [64]byte
@@ -0,0 +1,37 @@
+package networking
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+)
+
+func init() {
+	// Prefer protocol ping (verifies node ID), falling back to TCP connect probe
+	discovery.Liveness = append([]discovery.LivenessCheck{PingNode}, discovery.Liveness...)
+}
+
+// PingNode - send ping to node over node protocol, returning round trip time once node answers;
+// if ID is set, node must prove that ID
+func PingNode(addr string, id discovery.NodeID) (time.Duration, error) {
+	start := time.Now()
+
+	resp, err := newConnection("", addr, "ping", nil).request(id, nil)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if resp.Type != "pong" {
+		return 0, errors.New("unexpected response to ping: " + string(resp.Type))
+	}
+
+	return time.Since(start), nil
+}
+
+// handlePing - answer ping with pong
+func handlePing(conn *Connection, selfAddr string, connec net.Conn) error {
+	return respond(connec, newConnection(selfAddr, conn.InitNodeAddr, "pong", nil))
+}
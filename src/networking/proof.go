@@ -0,0 +1,83 @@
+package networking
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/core/types"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+)
+
+// ProofRequest - request for inclusion proof of transaction with specified hash
+type ProofRequest struct {
+	TxHash common.Hash `json:"txhash"`
+}
+
+// ProofResponse - transaction & its inclusion proof against checkpoint covering it; Error is set
+// if serving node could not produce proof
+type ProofResponse struct {
+	Transaction *types.Transaction `json:"transaction,omitempty"`
+	Proof       *types.TxProof     `json:"proof,omitempty"`
+	Error       string             `json:"error,omitempty"`
+}
+
+// RequestProof - request transaction with specified hash & its inclusion proof from node; the
+// proof must still be verified against checkpoint it refers to (see types.TxProof.Verify)
+func RequestProof(Db *discovery.NodeDatabase, node string, chainID common.Identifier, hash common.Hash) (*types.Transaction, *types.TxProof, error) {
+	resp, err := lightRequest(Db, node, chainID, "proofrequest", "proof", ProofRequest{TxHash: hash})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	proof := ProofResponse{}
+	err = json.NewDecoder(bytes.NewReader(resp.Data)).Decode(&proof)
+
+	if err != nil {
+		Db.Misbehave(node, resp.PeerID, discovery.ViolationUndecodable)
+		return nil, nil, err
+	}
+
+	if proof.Error != "" {
+		return nil, nil, errors.New(proof.Error)
+	}
+
+	if proof.Transaction == nil || proof.Proof == nil || proof.Transaction.SigningHash() != hash {
+		Db.Misbehave(node, resp.PeerID, discovery.ViolationInvalidChain)
+		return nil, nil, errors.New("proof response does not match requested transaction")
+	}
+
+	return proof.Transaction, proof.Proof, nil
+}
+
+// handleProofRequest - respond to proof request with transaction & inclusion proof from local chain
+func handleProofRequest(conn *Connection, Ch *types.Chain, connec net.Conn) error {
+	req := ProofRequest{}
+	err := json.NewDecoder(bytes.NewReader(conn.Data)).Decode(&req)
+
+	if err != nil {
+		return err
+	}
+
+	resp := ProofResponse{}
+
+	proof, err := Ch.ProveTransaction(req.TxHash)
+
+	if err != nil {
+		resp.Error = err.Error()
+	} else {
+		resp.Proof = proof
+
+		for _, tx := range Ch.Transactions {
+			if tx != nil && tx.Hash() == req.TxHash {
+				resp.Transaction = tx
+				break
+			}
+		}
+	}
+
+	return respondLight(conn, Ch, connec, "proof", resp)
+}
@@ -0,0 +1,94 @@
+package networking
+
+import (
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/core/token"
+	"github.com/mitsukomegumi/indo-go/src/core/types"
+)
+
+// RelayPolicy - admission rules applied to transactions before they are relayed or accepted
+type RelayPolicy struct {
+	StaleTolerance time.Duration // Maximum time witness may precede latest witness in local chain
+	MaxClockDrift  time.Duration // Maximum time witness may lie in the future
+}
+
+// Admission - policy used to admit relayed transactions
+var Admission = RelayPolicy{StaleTolerance: 10 * time.Minute, MaxClockDrift: 2 * time.Minute}
+
+var (
+	// ErrNotWitnessed - returned when relaying transaction without witness
+	ErrNotWitnessed = errors.New("operation not permitted; transaction not witnessed")
+
+	// ErrTxInChain - returned when received transaction has already been added to local chain
+	ErrTxInChain = errors.New("transaction already in chain")
+
+	// ErrTxStale - returned when transaction was witnessed too long before latest transaction in local chain
+	ErrTxStale = errors.New("transaction behind latest chain; stale transactions are not relayed")
+
+	// ErrTxFuture - returned when transaction witness time lies too far in the future
+	ErrTxFuture = errors.New("transaction witnessed in the future")
+
+	// ErrAlreadyRelayed - returned when transaction has already been relayed by current node
+	ErrAlreadyRelayed = errors.New("transaction already relayed")
+)
+
+// CheckAdmission - check transaction against local chain state (nil if none held)
+//
+// Transactions must carry valid signature of their sending account over hash matching their
+// contents; unsigned transactions are never admitted. A transaction is stale if its witness time
+// precedes the latest witness time in the local chain by more than the policy's stale tolerance;
+// stale transactions are neither relayed nor accepted, and must be re-witnessed by their sender.
+// Transactions must also satisfy the chain's spam policy, with transactions held in the chain's
+// mempool counted towards its rate limit.
+func (policy RelayPolicy) CheckAdmission(Tx *types.Transaction, Ch *types.Chain) error {
+	err := Tx.Verify()
+
+	if err != nil {
+		return err // Sending account must be authenticated before any per-account check
+	}
+
+	if reflect.ValueOf(Tx.InitialWitness).IsNil() {
+		return ErrNotWitnessed
+	}
+
+	witnessTime := Tx.InitialWitness.WitnessTime
+
+	if witnessTime.After(time.Now().UTC().Add(policy.MaxClockDrift)) {
+		return ErrTxFuture
+	}
+
+	if Ch == nil {
+		return nil
+	}
+
+	latest := Ch.LatestWitnessTime()
+
+	if !latest.IsZero() && witnessTime.Before(latest.Add(-policy.StaleTolerance)) {
+		return ErrTxStale
+	}
+
+	since := time.Now().UTC().Add(-Ch.SpamPolicy.RateWindow)
+	err = Ch.CheckSpam(Tx, MempoolFor(Ch.Identifier).CountFrom(Tx.SendingAccount.Address, Tx.Hash(), since))
+
+	if err != nil {
+		return err // Transaction lacks proof-of-work or sender is flooding chain
+	}
+
+	if token.IsTokenChain(Ch) {
+		return checkToken(Ch, Tx) // Token operation must be valid against current balances
+	}
+
+	return nil
+}
+
+// admitReceived - check transaction received from peer against local chain state
+func admitReceived(Tx *types.Transaction, Ch *types.Chain) error {
+	if Ch != nil && Ch.HasTransaction(Tx.Hash()) {
+		return ErrTxInChain
+	}
+
+	return Admission.CheckAdmission(Tx, Ch)
+}
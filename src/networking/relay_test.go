@@ -0,0 +1,110 @@
+package networking
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/core/types"
+)
+
+// testTransaction - transaction of specified nonce with work of specified difficulty, signed by key
+// & witnessed at specified time
+func testTransaction(t *testing.T, key *ecdsa.PrivateKey, nonce uint64, difficulty uint8, witnessed time.Time) *types.Transaction {
+	tx := types.NewTransaction(nonce, *types.NewAccount(types.PubkeyToAddress(&key.PublicKey)), common.HexToAddress("02"), types.NewAmount(1), nil, nil, nil)
+
+	for difficulty == 0 && tx.WorkBits() >= 8 {
+		tx.Data.Nonce += 100 // Make sure transaction starts without sufficient work
+	}
+
+	if err := tx.SolveWork(difficulty); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.SignWith(key); err != nil {
+		t.Fatal(err)
+	}
+
+	if !witnessed.IsZero() {
+		witness := types.NewWitness(1000, types.HexToSignature("01"), 100)
+		witness.WitnessTime = witnessed
+		tx.InitialWitness = &witness
+	}
+
+	return tx
+}
+
+func TestCheckAdmission(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+
+	ch := &types.Chain{Identifier: common.Identifier{0x5a}, SpamPolicy: types.SpamPolicy{Difficulty: 8}}
+	ch.AddTransaction(testTransaction(t, key, 0, 8, now))
+
+	tests := []struct {
+		name string
+		tx   *types.Transaction
+		ch   *types.Chain
+		err  error
+	}{
+		{"with work", testTransaction(t, key, 1, 8, now), ch, nil},
+		{"without work", testTransaction(t, key, 1, 0, now), ch, types.ErrInsufficientWork},
+		{"not witnessed", testTransaction(t, key, 1, 8, time.Time{}), ch, ErrNotWitnessed},
+		{"witnessed in future", testTransaction(t, key, 1, 8, now.Add(time.Hour)), ch, ErrTxFuture},
+		{"stale", testTransaction(t, key, 1, 8, now.Add(-time.Hour)), ch, ErrTxStale},
+		{"no local chain", testTransaction(t, key, 1, 0, now), nil, nil},
+	}
+
+	for _, test := range tests {
+		if err := Admission.CheckAdmission(test.tx, test.ch); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestAdmitReceivedForgedSender(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	ch := &types.Chain{Identifier: common.Identifier{0x5b}}
+
+	valid := testTransaction(t, key, 0, 0, now)
+
+	forged := *valid
+	forged.SendingAccount = *types.NewAccount(common.HexToAddress("03")) // Claims other sender, keeping signature
+
+	unsigned := *valid
+	unsigned.Signature, unsigned.PublicKey = nil, nil
+
+	tests := []struct {
+		name string
+		tx   *types.Transaction
+		err  error
+	}{
+		{"valid", valid, nil},
+		{"forged sender", &forged, types.ErrHashMismatch},
+		{"unsigned", &unsigned, types.ErrUnsigned},
+	}
+
+	for _, test := range tests {
+		if err := admitReceived(test.tx, ch); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+
+		if err := admitReceived(test.tx, nil); err != test.err {
+			t.Errorf("%s without local chain: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
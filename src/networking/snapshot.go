@@ -0,0 +1,94 @@
+package networking
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"strconv"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/core/types"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+)
+
+// SnapshotResponse - latest state snapshot of chain & checkpoint headers up to checkpoint it is taken
+// at; Error is set if serving node holds no snapshot
+type SnapshotResponse struct {
+	Headers  []*types.Header `json:"headers"`
+	Snapshot *types.Snapshot `json:"snapshot,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// RequestSnapshot - request latest snapshot of chain & checkpoint headers leading up to it from node;
+// both must still be verified (see types.Chain.BootstrapFromSnapshot)
+func RequestSnapshot(Db *discovery.NodeDatabase, node string, chainID common.Identifier) (*types.Snapshot, []*types.Header, error) {
+	resp, err := lightRequest(Db, node, chainID, "snapshotrequest", "snapshot", struct{}{})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	snapshot := SnapshotResponse{}
+	err = json.NewDecoder(bytes.NewReader(resp.Data)).Decode(&snapshot)
+
+	if err != nil {
+		Db.Misbehave(node, resp.PeerID, discovery.ViolationUndecodable)
+		return nil, nil, err
+	}
+
+	if snapshot.Error != "" {
+		return nil, nil, errors.New(snapshot.Error)
+	}
+
+	if snapshot.Snapshot == nil {
+		Db.Misbehave(node, resp.PeerID, discovery.ViolationInvalidChain)
+		return nil, nil, errors.New("snapshot response without snapshot")
+	}
+
+	return snapshot.Snapshot, snapshot.Headers, nil
+}
+
+// BootstrapFromSnapshot - initialize empty local chain from verified snapshot served by best node;
+// transactions following snapshot are left to SyncChain. Reputation recorded in snapshot is not
+// imported; local reputation is only earned through direct contact.
+func BootstrapFromSnapshot(Ch *types.Chain, Db *discovery.NodeDatabase) error {
+	node := Db.FindNode()
+
+	s, headers, err := RequestSnapshot(Db, node, Ch.Identifier)
+
+	if err != nil {
+		return err
+	}
+
+	if Ch.NodeDb == nil {
+		Ch.NodeDb = Db
+	}
+
+	err = Ch.BootstrapFromSnapshot(headers, s, Ch.WitnessReputation)
+
+	if err == types.ErrInsufficientSignatures {
+		return err // Not enough reputable witnesses known yet; not misbehavior of serving node
+	} else if err != nil {
+		Db.Misbehave(node, Db.PeerID(node), discovery.ViolationInvalidChain)
+		return err
+	}
+
+	common.ThrowSuccess("bootstrapped chain from snapshot at checkpoint " + strconv.Itoa(s.Checkpoint) + " (version " + strconv.Itoa(s.Version) + ")")
+
+	return Ch.WriteChainToMemory(common.GetCurrentDir())
+}
+
+// handleSnapshotRequest - respond to snapshot request with latest snapshot of local chain
+func handleSnapshotRequest(conn *Connection, Ch *types.Chain, connec net.Conn) error {
+	resp := SnapshotResponse{}
+
+	if Ch.Snapshot == nil || Ch.Snapshot.Checkpoint > len(Ch.Checkpoints) {
+		resp.Error = types.ErrNoSnapshot.Error()
+	} else {
+		resp.Snapshot = Ch.Snapshot
+		resp.Headers = Ch.Checkpoints[:Ch.Snapshot.Checkpoint]
+	}
+
+	return respondLight(conn, Ch, connec, "snapshot", resp)
+}
@@ -0,0 +1,325 @@
+package networking
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/core/types"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+)
+
+const (
+	// SyncBatchSize - number of transactions requested per sync batch
+	SyncBatchSize = 100
+
+	// maxSyncBatchSize - maximum number of transactions served in single sync batch
+	maxSyncBatchSize = 500
+
+	// syncRetries - number of consecutive failed requests after which sync is abandoned
+	syncRetries = 3
+
+	// syncMarkerExpiry - time after which sync marker no longer refreshed (e.g. left by crashed
+	// process) is ignored; marker is refreshed before every batch request
+	syncMarkerExpiry = time.Minute
+)
+
+// ErrChainNotEmpty - error returned when full chain is relayed to node already holding transactions of chain
+var ErrChainNotEmpty = errors.New("full chains only accepted to bootstrap empty local chain")
+
+// IsSyncing - check if chain with specified identifier is currently being synced by any node process
+// sharing current directory; relayed transactions are held in mempool until sync completes
+func IsSyncing(id common.Identifier) bool {
+	info, err := os.Stat(syncMarkerPath(id))
+
+	return err == nil && time.Since(info.ModTime()) < syncMarkerExpiry
+}
+
+// syncMarkerPath - path of marker file present while chain with specified identifier is being synced
+func syncMarkerPath(id common.Identifier) string {
+	return common.GetCurrentDir() + id.String() + "Sync.lock"
+}
+
+// markSyncing - create or refresh sync marker of chain with specified identifier
+func markSyncing(id common.Identifier) error {
+	return os.WriteFile(syncMarkerPath(id), []byte(time.Now().UTC().String()), 0644)
+}
+
+// clearSyncing - remove sync marker of chain with specified identifier
+func clearSyncing(id common.Identifier) {
+	os.Remove(syncMarkerPath(id))
+}
+
+// SyncRequest - request for transactions following specified chain version
+type SyncRequest struct {
+	Version int `json:"version"`
+	Limit   int `json:"limit"`
+}
+
+// SyncBatch - page of transactions following requested chain version
+type SyncBatch struct {
+	Transactions []*types.Transaction `json:"transactions"`
+	Checkpoints  []*types.Header      `json:"checkpoints,omitempty"` // Checkpoints covering served transactions
+	Snapshot     *types.Snapshot      `json:"snapshot,omitempty"`    // Latest snapshot, if taken at served checkpoint
+
+	Version int  `json:"version"` // Current version of serving node's chain
+	More    bool `json:"more"`
+
+	Pruned int `json:"pruned,omitempty"` // Set to last pruned version if requested transactions were pruned
+}
+
+// SyncChain - bring local chain up to date with best node, fetching only missing transactions
+// in paged batches. Progress is written to memory after each batch, so an interrupted sync
+// resumes from the last applied version. Empty chains are bootstrapped from latest snapshot,
+// or via full chain transfer if no snapshot can be verified.
+func SyncChain(Ch *types.Chain, Db *discovery.NodeDatabase) error {
+	if Ch.Version == 0 && len(Ch.Transactions) == 0 {
+		err := BootstrapFromSnapshot(Ch, Db)
+
+		if err != nil {
+			common.ThrowWarning("local chain empty; snapshot unavailable (" + err.Error() + "); bootstrapping from full chain")
+			return FetchChainWithAdd(Ch, Db)
+		}
+	}
+
+	defer clearSyncing(Ch.Identifier)
+
+	node := Db.FindNode()
+	failures := 0
+
+	for {
+		err := markSyncing(Ch.Identifier)
+
+		if err != nil {
+			return err
+		}
+
+		batch, err := requestSyncBatch(Db, node, Ch.Identifier, Ch.Version, SyncBatchSize)
+
+		if err != nil {
+			failures++
+
+			if failures > syncRetries {
+				return err
+			}
+
+			common.ThrowWarning("sync interrupted (" + err.Error() + "); resuming from version " + strconv.Itoa(Ch.Version))
+			time.Sleep(rDelay)
+
+			continue
+		}
+
+		failures = 0
+
+		if batch.Pruned > Ch.Version {
+			return errors.New("best node pruned transactions up to version " + strconv.Itoa(batch.Pruned) + "; bootstrap from snapshot")
+		}
+
+		next, err := applyBatch(Ch, batch)
+
+		if err != nil {
+			Db.Misbehave(node, Db.PeerID(node), discovery.ViolationInvalidChain)
+			return err
+		}
+
+		*Ch = *next // Batch fully verified
+
+		for _, tx := range batch.Transactions {
+			MempoolFor(Ch.Identifier).Remove(tx.Hash()) // Already included in chain
+		}
+
+		err = Ch.WriteChainToMemory(common.GetCurrentDir()) // Persist progress
+
+		if err != nil {
+			return err
+		}
+
+		common.ThrowSuccess("synced chain to version " + strconv.Itoa(Ch.Version) + " of " + strconv.Itoa(batch.Version))
+
+		if !batch.More || len(batch.Transactions) == 0 {
+			return nil
+		}
+	}
+}
+
+// applyBatch - verify sync batch against local chain, returning copy of chain extended by batch; local
+// chain itself is left untouched, so batches failing verification part way leave no trace
+func applyBatch(Ch *types.Chain, batch *SyncBatch) (*types.Chain, error) {
+	err := Ch.ValidateBatch(batch.Transactions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	next := *Ch
+	next.Transactions = Ch.Transactions[:len(Ch.Transactions):len(Ch.Transactions)] // Appends must not share backing array
+	next.Checkpoints = Ch.Checkpoints[:len(Ch.Checkpoints):len(Ch.Checkpoints)]
+
+	for _, tx := range batch.Transactions {
+		next.AddTransaction(tx)
+	}
+
+	for _, h := range batch.Checkpoints {
+		if h == nil || h.Number != len(next.Checkpoints)+1 {
+			break // Gap left by earlier skipped checkpoint; later checkpoints cannot be verified
+		}
+
+		err = next.AddCheckpoint(h, next.WitnessReputation)
+
+		if err == types.ErrInsufficientSignatures {
+			common.ThrowWarning("skipping checkpoint " + strconv.Itoa(h.Number) + ": " + err.Error())
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	if batch.Snapshot != nil {
+		err = addSnapshot(&next, batch.Snapshot)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &next, nil
+}
+
+// applyFullChain - verify chain relayed in full through same checks as sync batches, returning verified copy
+// of empty local chain extended by it; chains pruned up to snapshot are bootstrapped from snapshot first
+func applyFullChain(Ch *types.Chain, chain *types.Chain) (*types.Chain, error) {
+	if Ch.Version != 0 || len(Ch.Transactions) != 0 {
+		return nil, ErrChainNotEmpty
+	}
+
+	batch := &SyncBatch{Transactions: chain.Transactions, Checkpoints: chain.Checkpoints, Snapshot: chain.Snapshot}
+
+	if s := chain.Snapshot; s != nil && (len(chain.Transactions) == 0 || chain.Transactions[0].ChainVersion != 1) {
+		if s.Checkpoint < 1 || s.Checkpoint > len(chain.Checkpoints) {
+			return nil, types.ErrSnapshotCheckpoint
+		}
+
+		base := *Ch
+		err := base.BootstrapFromSnapshot(chain.Checkpoints[:s.Checkpoint], s, base.WitnessReputation)
+
+		if err != nil {
+			return nil, err
+		}
+
+		batch.Checkpoints = chain.Checkpoints[s.Checkpoint:]
+		batch.Snapshot = nil
+		Ch = &base
+	}
+
+	return applyBatch(Ch, batch)
+}
+
+// addSnapshot - add snapshot served during sync to local chain, pruning covered transactions if snapshot
+// policy says so; snapshots lacking reputable signatures or taken at skipped checkpoints are ignored
+func addSnapshot(Ch *types.Chain, s *types.Snapshot) error {
+	if s.Checkpoint > len(Ch.Checkpoints) {
+		return nil // Taken at checkpoint skipped above
+	}
+
+	err := Ch.AddSnapshot(s, Ch.WitnessReputation)
+
+	if err == types.ErrInsufficientSignatures || err == types.ErrStaleSnapshot {
+		common.ThrowWarning("skipping snapshot at checkpoint " + strconv.Itoa(s.Checkpoint) + ": " + err.Error())
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if types.Snapshotting.Prune {
+		common.ThrowSuccess("pruned " + strconv.Itoa(Ch.Prune()) + " transactions covered by snapshot")
+	}
+
+	return nil
+}
+
+// requestSyncBatch - request single batch of transactions of specified chain following specified version from node
+func requestSyncBatch(Db *discovery.NodeDatabase, node string, chainID common.Identifier, version int, limit int) (*SyncBatch, error) {
+	reqBytes, err := json.Marshal(SyncRequest{Version: version, Limit: limit})
+
+	if err != nil {
+		return nil, err
+	}
+
+	conn := newConnection(Db.SelfAddr, node, "syncrequest", reqBytes)
+	conn.ChainID = chainID
+
+	resp, err := conn.request(Db.PeerID(node), Db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Type != "syncbatch" {
+		return nil, errors.New("unexpected response to sync request: " + string(resp.Type))
+	}
+
+	batch := SyncBatch{}
+	err = json.NewDecoder(bytes.NewReader(resp.Data)).Decode(&batch)
+
+	if err != nil {
+		Db.Misbehave(node, resp.PeerID, discovery.ViolationUndecodable)
+		return nil, err
+	}
+
+	return &batch, nil
+}
+
+// handleSyncRequest - respond to sync request with transactions from local chain
+func handleSyncRequest(conn *Connection, Ch *types.Chain, connec net.Conn) error {
+	req := SyncRequest{}
+	err := json.NewDecoder(bytes.NewReader(conn.Data)).Decode(&req)
+
+	if err != nil {
+		return err
+	}
+
+	if req.Limit <= 0 || req.Limit > maxSyncBatchSize {
+		req.Limit = maxSyncBatchSize
+	}
+
+	batch := SyncBatch{Version: Ch.Version}
+
+	if pruned := Ch.PrunedVersion(); req.Version < pruned {
+		batch.Pruned = pruned // Requester must bootstrap from snapshot
+	} else {
+		batch.Transactions = Ch.TransactionsSince(req.Version, req.Limit)
+	}
+
+	if txs := batch.Transactions; len(txs) > 0 {
+		batch.More = txs[len(txs)-1].ChainVersion < Ch.Version
+		batch.Checkpoints = Ch.CheckpointsSince(req.Version, txs[len(txs)-1].ChainVersion)
+	}
+
+	for _, h := range batch.Checkpoints {
+		if Ch.Snapshot != nil && h.Number == Ch.Snapshot.Checkpoint {
+			batch.Snapshot = Ch.Snapshot
+		}
+	}
+
+	batchBytes, err := json.Marshal(batch)
+
+	if err != nil {
+		return err
+	}
+
+	selfAddr := ""
+
+	if Ch.NodeDb != nil {
+		selfAddr = Ch.NodeDb.SelfAddr
+	}
+
+	resp := newConnection(selfAddr, conn.InitNodeAddr, "syncbatch", batchBytes)
+	resp.ChainID = Ch.Identifier
+
+	return respond(connec, resp)
+}
@@ -0,0 +1,136 @@
+package networking
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/core/types"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+)
+
+func TestIsSyncing(t *testing.T) {
+	id, other := common.Identifier{0x5b}, common.Identifier{0x5c}
+
+	defer clearSyncing(id)
+
+	if err := markSyncing(id); err != nil {
+		t.Fatal(err)
+	}
+
+	stale := time.Now().Add(-2 * syncMarkerExpiry)
+
+	tests := []struct {
+		name    string
+		id      common.Identifier
+		update  func()
+		syncing bool
+	}{
+		{"marked", id, func() {}, true},
+		{"other chain", other, func() {}, false},
+		{"stale marker", id, func() { os.Chtimes(syncMarkerPath(id), stale, stale) }, false},
+		{"refreshed marker", id, func() { markSyncing(id) }, true},
+		{"cleared", id, func() { clearSyncing(id) }, false},
+	}
+
+	for _, test := range tests {
+		test.update()
+
+		if IsSyncing(test.id) != test.syncing {
+			t.Errorf("%s: expected syncing %v", test.name, test.syncing)
+		}
+	}
+}
+
+func TestWitnessPendingWhileSyncing(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch := &types.Chain{Identifier: common.Identifier{0x5d}, NodeDb: &discovery.NodeDatabase{}}
+	witness := types.NewWitness(1000, types.HexToSignature("01"), 100)
+
+	defer os.Remove(types.ChainPath(common.GetCurrentDir(), ch.Identifier))
+	defer clearSyncing(ch.Identifier)
+
+	pool := MempoolFor(ch.Identifier)
+
+	if err := pool.Add(testTransaction(t, key, 0, 0, time.Now().UTC())); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		syncing bool
+		pending int
+		version int
+	}{
+		{"syncing", true, 1, 0},
+		{"synced", false, 0, 1},
+	}
+
+	for _, test := range tests {
+		if test.syncing {
+			markSyncing(ch.Identifier)
+		} else {
+			clearSyncing(ch.Identifier)
+		}
+
+		witnessPending(ch, &witness)
+
+		if pool.Len() != test.pending || ch.Version != test.version {
+			t.Errorf("%s: expected %d pending at version %d, got %d at version %d", test.name, test.pending, test.version, pool.Len(), ch.Version)
+		}
+	}
+}
+
+func TestApplyFullChain(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host := &types.Chain{Identifier: common.Identifier{0x5e}}
+
+	for i := 0; i < 3; i++ {
+		host.AddTransaction(testTransaction(t, key, uint64(i), 0, time.Now().UTC()))
+	}
+
+	local := &types.Chain{Identifier: host.Identifier}
+	local.AddTransaction(host.Transactions[0])
+
+	forged := *host.Transactions[1]
+	forged.Data.Amount = types.NewAmount(1000)
+
+	tampered := *host
+	tampered.Transactions = []*types.Transaction{host.Transactions[0], &forged, host.Transactions[2]}
+
+	tests := []struct {
+		name    string
+		local   *types.Chain
+		chain   *types.Chain
+		version int
+		valid   bool
+	}{
+		{"empty local chain", &types.Chain{Identifier: host.Identifier}, host, 3, true},
+		{"non-empty local chain", local, host, 0, false},
+		{"forged transaction", &types.Chain{Identifier: host.Identifier}, &tampered, 0, false},
+	}
+
+	for _, test := range tests {
+		next, err := applyFullChain(test.local, test.chain)
+
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %v, got %v", test.name, test.valid, err)
+		} else if err == nil && next.Version != test.version {
+			t.Errorf("%s: expected version %d, got %d", test.name, test.version, next.Version)
+		}
+	}
+}
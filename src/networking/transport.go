@@ -0,0 +1,602 @@
+package networking
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+)
+
+const (
+	// maxFrameSize - maximum plaintext size of single encrypted frame
+	maxFrameSize = 1 << 20
+
+	// maxHandshakeSize - maximum size of single handshake message
+	maxHandshakeSize = 4096
+
+	handshakeTimeout = 10 * time.Second
+
+	// handshakeVersion - version of handshake protocol; bumped whenever transcript changes
+	handshakeVersion = 2
+)
+
+var (
+	// ErrHandshakeFailed - returned when peer could not prove ownership of its node ID
+	ErrHandshakeFailed = errors.New("handshake failed: peer identity could not be verified")
+
+	// ErrUnexpectedPeer - returned when dialed peer proves an ID other than the one expected
+	ErrUnexpectedPeer = errors.New("handshake failed: peer identity does not match expected node id")
+
+	// ErrProtocolVersion - returned when peer speaks other handshake version; not misbehavior, as
+	// peers are upgraded independently
+	ErrProtocolVersion = errors.New("handshake failed: peer uses incompatible protocol version")
+
+	// ErrBannedPeer - returned when dialed peer is banned
+	ErrBannedPeer = errors.New("peer is banned")
+
+	// ErrFrameTooLarge - returned when peer sends frame exceeding maximum frame size
+	ErrFrameTooLarge = errors.New("frame too large")
+
+	// ErrFrameAuthFailed - returned when frame fails authentication (tampered or forged)
+	ErrFrameAuthFailed = errors.New("frame authentication failed")
+
+	keyOnce sync.Once
+	selfKey *ecdsa.PrivateKey
+	keyErr  error
+)
+
+// SecureConn - authenticated, encrypted connection between two nodes
+type SecureConn struct {
+	net.Conn
+
+	RemoteID     discovery.NodeID // Node ID proven by peer during handshake
+	ObservedAddr string           // Address of current node as observed by peer; reported (& signed) during handshake
+
+	enc, dec           cipher.AEAD
+	encNonce, decNonce uint64
+
+	readBuf []byte
+	eof     bool
+
+	wMu    sync.Mutex
+	wClose bool
+}
+
+// handshakeMsg - single message exchanged during connection handshake
+type handshakeMsg struct {
+	Version   int    `json:"version,omitempty"` // Unset by nodes preceding versioned handshake (version 1)
+	Ephemeral []byte `json:"ephemeral,omitempty"`
+	ID        []byte `json:"id,omitempty"`
+	Observed  string `json:"observed,omitempty"` // Address sender observes receiver connecting from
+	Signature []byte `json:"signature,omitempty"`
+}
+
+// GetNodeKey - load node identity key from memory, generating & persisting new key if none exists
+func GetNodeKey() (*ecdsa.PrivateKey, error) {
+	keyOnce.Do(func() {
+		selfKey, keyErr = discovery.ReadNodeKeyFromMemory(common.GetCurrentDir())
+
+		if keyErr != nil {
+			if !strings.Contains(keyErr.Error(), "no such file") && !strings.Contains(keyErr.Error(), "cannot find the file") {
+				return
+			}
+
+			common.ThrowWarning("no node key found; generating new node identity")
+
+			selfKey, keyErr = discovery.NewNodeKey()
+
+			if keyErr != nil {
+				return
+			}
+
+			keyErr = discovery.WriteNodeKeyToMemory(selfKey, common.GetCurrentDir())
+		}
+	})
+
+	return selfKey, keyErr
+}
+
+// SetNodeKey - set identity key used to authenticate current node to peers
+func SetNodeKey(key *ecdsa.PrivateKey) {
+	keyOnce.Do(func() {})
+	selfKey = key
+	keyErr = nil
+}
+
+// dial - open authenticated, encrypted connection to node at specified address; banned peers
+// (by address or proven ID) are refused
+func dial(addr string, expected discovery.NodeID, Db *discovery.NodeDatabase) (*SecureConn, error) {
+	if Db.IsBanned(addr, expected) {
+		return nil, ErrBannedPeer
+	}
+
+	key, err := GetNodeKey()
+
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialTimeout("tcp", discovery.HostPort(addr, discovery.DefaultPort), timeout) // Connect to peer addr
+
+	if err != nil {
+		return nil, err
+	}
+
+	sConn, err := newSecureConn(conn, key, true)
+
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if expected != (discovery.NodeID{}) && sConn.RemoteID != expected {
+		conn.Close()
+		return nil, ErrUnexpectedPeer
+	}
+
+	if Db.IsBanned("", sConn.RemoteID) {
+		conn.Close()
+		return nil, ErrBannedPeer
+	}
+
+	observeExternalAddr(Db, remoteHost(conn), sConn.RemoteID, sConn.ObservedAddr)
+
+	return sConn, nil
+}
+
+// accept - accept peer connection on specified listener, completing handshake; connections from
+// banned peers & peers failing handshake are dropped, and the next connection is accepted
+func accept(ln net.Listener, Db *discovery.NodeDatabase) (*SecureConn, error) {
+	key, err := GetNodeKey()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		conn, err := ln.Accept() // Accept peer connection
+
+		if err != nil {
+			return nil, err
+		}
+
+		host := remoteHost(conn)
+
+		if Db.IsBanned(host, discovery.NodeID{}) {
+			common.ThrowWarning("refusing connection from banned peer " + host)
+			conn.Close()
+			continue
+		}
+
+		sConn, err := newSecureConn(conn, key, false)
+
+		if err != nil {
+			common.ThrowWarning("handshake with " + host + " failed: " + err.Error())
+
+			if err == ErrHandshakeFailed {
+				Db.Misbehave(host, discovery.NodeID{}, discovery.ViolationInvalidSignature)
+			}
+
+			conn.Close()
+			continue
+		}
+
+		if Db.IsBanned(host, sConn.RemoteID) {
+			common.ThrowWarning("refusing connection from banned peer " + sConn.RemoteID.String())
+			conn.Close()
+			continue
+		}
+
+		return sConn, nil
+	}
+}
+
+// remoteHost - address of peer on other end of connection, without port
+func remoteHost(conn net.Conn) string {
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+
+	if err != nil {
+		return conn.RemoteAddr().String()
+	}
+
+	return host
+}
+
+// newSecureConn - perform handshake over specified connection, proving ownership of key
+// and verifying peer's ownership of its node ID
+//
+// Initiator and responder exchange ephemeral ECDH keys; each side then signs the full
+// transcript (both ephemeral keys, its own ID & the address it observes the peer at) with its
+// identity key. Session keys are
+// derived from the ephemeral shared secret, bound to the transcript.
+func newSecureConn(conn net.Conn, key *ecdsa.PrivateKey, initiator bool) (*SecureConn, error) {
+	conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	defer conn.SetDeadline(time.Time{})
+
+	ephemeral, err := ecdh.P256().GenerateKey(rand.Reader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	selfID := discovery.PubkeyToNodeID(&key.PublicKey)
+
+	var initEph, respEph []byte
+	var remoteID discovery.NodeID
+	var observed string
+
+	peerAddr := []byte(remoteHost(conn))
+
+	if initiator {
+		initEph = ephemeral.PublicKey().Bytes()
+
+		err = writeHandshakeMsg(conn, handshakeMsg{Ephemeral: initEph})
+
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := readHandshakeMsg(conn)
+
+		if err != nil {
+			return nil, err
+		}
+
+		respEph = resp.Ephemeral
+
+		remoteID, err = verifyHandshakeMsg(resp, handshakeTranscript(initEph, respEph, resp.ID, []byte(resp.Observed)))
+
+		if err != nil {
+			return nil, err
+		}
+
+		observed = resp.Observed
+
+		sig, err := ecdsa.SignASN1(rand.Reader, key, handshakeTranscript(initEph, respEph, resp.ID, []byte(resp.Observed), selfID[:], peerAddr))
+
+		if err != nil {
+			return nil, err
+		}
+
+		err = writeHandshakeMsg(conn, handshakeMsg{ID: selfID[:], Observed: string(peerAddr), Signature: sig})
+
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		init, err := readHandshakeMsg(conn)
+
+		if err != nil {
+			return nil, err
+		}
+
+		initEph = init.Ephemeral
+		respEph = ephemeral.PublicKey().Bytes()
+
+		sig, err := ecdsa.SignASN1(rand.Reader, key, handshakeTranscript(initEph, respEph, selfID[:], peerAddr))
+
+		if err != nil {
+			return nil, err
+		}
+
+		err = writeHandshakeMsg(conn, handshakeMsg{Ephemeral: respEph, ID: selfID[:], Observed: string(peerAddr), Signature: sig})
+
+		if err != nil {
+			return nil, err
+		}
+
+		fin, err := readHandshakeMsg(conn)
+
+		if err != nil {
+			return nil, err
+		}
+
+		remoteID, err = verifyHandshakeMsg(fin, handshakeTranscript(initEph, respEph, selfID[:], peerAddr, fin.ID, []byte(fin.Observed)))
+
+		if err != nil {
+			return nil, err
+		}
+
+		observed = fin.Observed
+	}
+
+	peerEph := respEph
+
+	if !initiator {
+		peerEph = initEph
+	}
+
+	peerPub, err := ecdh.P256().NewPublicKey(peerEph)
+
+	if err != nil {
+		return nil, ErrHandshakeFailed
+	}
+
+	secret, err := ephemeral.ECDH(peerPub)
+
+	if err != nil {
+		return nil, ErrHandshakeFailed
+	}
+
+	transcript := handshakeTranscript(initEph, respEph)
+
+	initKey := deriveKey(secret, transcript, "initiator")
+	respKey := deriveKey(secret, transcript, "responder")
+
+	sConn := &SecureConn{Conn: conn, RemoteID: remoteID, ObservedAddr: observed}
+
+	if initiator {
+		sConn.enc, err = newAEAD(initKey)
+		if err == nil {
+			sConn.dec, err = newAEAD(respKey)
+		}
+	} else {
+		sConn.enc, err = newAEAD(respKey)
+		if err == nil {
+			sConn.dec, err = newAEAD(initKey)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return sConn, nil
+}
+
+// Read - read decrypted data from connection; returns io.EOF once peer has closed its side
+func (conn *SecureConn) Read(b []byte) (int, error) {
+	for len(conn.readBuf) == 0 {
+		if conn.eof {
+			return 0, io.EOF
+		}
+
+		frame, err := conn.readFrame()
+
+		if err != nil {
+			return 0, err
+		}
+
+		if len(frame) == 0 {
+			conn.eof = true
+		}
+
+		conn.readBuf = frame
+	}
+
+	n := copy(b, conn.readBuf)
+	conn.readBuf = conn.readBuf[n:]
+
+	return n, nil
+}
+
+// Write - encrypt & write data to connection
+func (conn *SecureConn) Write(b []byte) (int, error) {
+	conn.wMu.Lock()
+	defer conn.wMu.Unlock()
+
+	if conn.wClose {
+		return 0, errors.New("write on closed connection")
+	}
+
+	written := 0
+
+	for written < len(b) {
+		end := written + maxFrameSize
+
+		if end > len(b) {
+			end = len(b)
+		}
+
+		err := conn.writeFrame(b[written:end])
+
+		if err != nil {
+			return written, err
+		}
+
+		written = end
+	}
+
+	return written, nil
+}
+
+// CloseWrite - signal to peer that no further data will be written
+func (conn *SecureConn) CloseWrite() error {
+	conn.wMu.Lock()
+	defer conn.wMu.Unlock()
+
+	if conn.wClose {
+		return nil
+	}
+
+	conn.wClose = true
+
+	return conn.writeFrame(nil)
+}
+
+// Close - signal end of data to peer, close underlying connection
+func (conn *SecureConn) Close() error {
+	conn.CloseWrite()
+	return conn.Conn.Close()
+}
+
+func (conn *SecureConn) writeFrame(b []byte) error {
+	sealed := conn.enc.Seal(nil, frameNonce(conn.encNonce), b, nil)
+	conn.encNonce++
+
+	frame := make([]byte, 4+len(sealed))
+	binary.BigEndian.PutUint32(frame, uint32(len(sealed)))
+	copy(frame[4:], sealed)
+
+	_, err := conn.Conn.Write(frame)
+
+	return err
+}
+
+func (conn *SecureConn) readFrame() ([]byte, error) {
+	header := make([]byte, 4)
+
+	_, err := io.ReadFull(conn.Conn, header)
+
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF // Peer closed without authenticated close frame
+		}
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header)
+
+	if size > maxFrameSize+uint32(conn.dec.Overhead()) {
+		return nil, ErrFrameTooLarge
+	}
+
+	sealed := make([]byte, size)
+
+	_, err = io.ReadFull(conn.Conn, sealed)
+
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := conn.dec.Open(nil, frameNonce(conn.decNonce), sealed, nil)
+
+	if err != nil {
+		return nil, ErrFrameAuthFailed
+	}
+
+	conn.decNonce++
+
+	return plain, nil
+}
+
+func writeHandshakeMsg(w io.Writer, msg handshakeMsg) error {
+	msg.Version = handshakeVersion
+
+	b, err := json.Marshal(msg)
+
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, 2)
+	binary.BigEndian.PutUint16(header, uint16(len(b)))
+
+	_, err = w.Write(append(header, b...))
+
+	return err
+}
+
+func readHandshakeMsg(r io.Reader) (handshakeMsg, error) {
+	msg := handshakeMsg{}
+	header := make([]byte, 2)
+
+	_, err := io.ReadFull(r, header)
+
+	if err != nil {
+		return msg, err
+	}
+
+	size := binary.BigEndian.Uint16(header)
+
+	if size > maxHandshakeSize {
+		return msg, ErrHandshakeFailed
+	}
+
+	b := make([]byte, size)
+
+	_, err = io.ReadFull(r, b)
+
+	if err != nil {
+		return msg, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(b)).Decode(&msg)
+
+	if err == nil && msg.Version != handshakeVersion {
+		return msg, ErrProtocolVersion
+	}
+
+	return msg, err
+}
+
+// verifyHandshakeMsg - check that message is signed by key belonging to the ID it carries
+func verifyHandshakeMsg(msg handshakeMsg, transcript []byte) (discovery.NodeID, error) {
+	id := discovery.NodeID{}
+
+	if len(msg.ID) != len(id) {
+		return id, ErrHandshakeFailed
+	}
+
+	copy(id[:], msg.ID)
+
+	pub, err := id.Pubkey()
+
+	if err != nil {
+		return id, ErrHandshakeFailed
+	}
+
+	if !ecdsa.VerifyASN1(pub, transcript, msg.Signature) {
+		return id, ErrHandshakeFailed
+	}
+
+	return id, nil
+}
+
+// handshakeTranscript - hash of all handshake values in specified order
+func handshakeTranscript(parts ...[]byte) []byte {
+	hash := sha256.New()
+	hash.Write([]byte("indo-handshake-v2"))
+
+	for _, part := range parts {
+		length := make([]byte, 4)
+		binary.BigEndian.PutUint32(length, uint32(len(part)))
+		hash.Write(length)
+		hash.Write(part)
+	}
+
+	return hash.Sum(nil)
+}
+
+// deriveKey - derive single-direction session key from shared secret (HKDF-SHA256)
+func deriveKey(secret []byte, salt []byte, label string) []byte {
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(secret)
+
+	expand := hmac.New(sha256.New, extract.Sum(nil))
+	expand.Write([]byte(label))
+	expand.Write([]byte{1})
+
+	return expand.Sum(nil)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func frameNonce(counter uint64) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[4:], counter)
+	return nonce
+}
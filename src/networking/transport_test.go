@@ -0,0 +1,170 @@
+package networking
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
+)
+
+func TestSecureConnHandshake(t *testing.T) {
+	initKey, _ := discovery.NewNodeKey()
+	respKey, _ := discovery.NewNodeKey()
+
+	a, b := net.Pipe()
+
+	type result struct {
+		conn *SecureConn
+		err  error
+	}
+
+	respCh := make(chan result)
+
+	go func() {
+		conn, err := newSecureConn(b, respKey, false)
+		respCh <- result{conn, err}
+	}()
+
+	initConn, err := newSecureConn(a, initKey, true)
+
+	if err != nil {
+		t.Fatalf("initiator handshake failed: %s", err.Error())
+	}
+
+	resp := <-respCh
+
+	if resp.err != nil {
+		t.Fatalf("responder handshake failed: %s", resp.err.Error())
+	}
+
+	if initConn.RemoteID != discovery.PubkeyToNodeID(&respKey.PublicKey) {
+		t.Errorf("initiator did not verify responder id")
+	}
+
+	if resp.conn.RemoteID != discovery.PubkeyToNodeID(&initKey.PublicKey) {
+		t.Errorf("responder did not verify initiator id")
+	}
+
+	if initConn.ObservedAddr != "pipe" || resp.conn.ObservedAddr != "pipe" {
+		t.Errorf("observed addresses not exchanged: %q, %q", initConn.ObservedAddr, resp.conn.ObservedAddr)
+	}
+
+	go func() {
+		initConn.Write([]byte("relay"))
+		initConn.CloseWrite()
+	}()
+
+	b2, err := ioutil.ReadAll(resp.conn)
+
+	if err != nil || string(b2) != "relay" {
+		t.Errorf("unexpected data %q (%v)", b2, err)
+	}
+}
+
+func TestSecureConnRejectsForgedID(t *testing.T) {
+	initKey, _ := discovery.NewNodeKey()
+	respKey, _ := discovery.NewNodeKey()
+	otherKey, _ := discovery.NewNodeKey()
+
+	a, b := net.Pipe()
+
+	go func() {
+		// Responder asserts another node's ID without holding its key
+		init, err := readHandshakeMsg(b)
+		if err != nil {
+			return
+		}
+		sig, _ := respKey.Sign(nil, handshakeTranscript(init.Ephemeral), nil)
+		forged := discovery.PubkeyToNodeID(&otherKey.PublicKey)
+		writeHandshakeMsg(b, handshakeMsg{Ephemeral: init.Ephemeral, ID: forged[:], Signature: sig})
+		b.Close()
+	}()
+
+	_, err := newSecureConn(a, initKey, true)
+
+	if err != ErrHandshakeFailed {
+		t.Errorf("expected forged identity to be rejected, got %v", err)
+	}
+}
+
+func TestSecureConnProtocolVersion(t *testing.T) {
+	respKey, _ := discovery.NewNodeKey()
+
+	tests := []struct {
+		name    string
+		version int
+		err     error
+	}{
+		{"unversioned peer", 0, ErrProtocolVersion},
+		{"newer peer", handshakeVersion + 1, ErrProtocolVersion},
+	}
+
+	for _, test := range tests {
+		a, b := net.Pipe()
+
+		go func(version int) {
+			msg, _ := json.Marshal(handshakeMsg{Version: version, Ephemeral: []byte("ephemeral")})
+			header := make([]byte, 2)
+			binary.BigEndian.PutUint16(header, uint16(len(msg)))
+			a.Write(append(header, msg...))
+			a.Close()
+		}(test.version)
+
+		if _, err := newSecureConn(b, respKey, false); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestExternalAddrVote(t *testing.T) {
+	votes := &addrVotes{votes: make(map[string]addrVote)}
+
+	peer1, peer2, peer3, peer4 := discovery.RandomNodeID(), discovery.RandomNodeID(), discovery.RandomNodeID(), discovery.RandomNodeID()
+
+	tests := []struct {
+		name  string
+		voter string
+		id    discovery.NodeID
+		addr  string
+		want  string
+		found bool
+	}{
+		{"below quorum", "1.1.1.1", peer1, "8.8.4.4", "", false},
+		{"same node from other address", "5.5.5.5", peer1, "8.8.4.4", "", false},
+		{"quorum reached", "2.2.2.2", peer2, "8.8.4.4", "8.8.4.4", true},
+		{"minority report", "3.3.3.3", peer3, "9.9.9.9", "8.8.4.4", true},
+		{"peer changes its report", "1.1.1.1", peer1, "9.9.9.9", "9.9.9.9", true},
+		{"no majority", "4.4.4.4", peer4, "8.8.4.4", "", false},
+	}
+
+	for _, test := range tests {
+		votes.vote(test.voter, test.id, test.addr)
+
+		if addr, found := votes.majority(); found != test.found || addr != test.want {
+			t.Errorf("%s: expected %q (%v), got %q (%v)", test.name, test.want, test.found, addr, found)
+		}
+	}
+}
+
+func TestObserveExternalAddr(t *testing.T) {
+	defer func(votes *addrVotes) { externalAddrs = votes }(externalAddrs)
+
+	externalAddrs = &addrVotes{votes: make(map[string]addrVote)}
+	db := &discovery.NodeDatabase{SelfRef: discovery.RandomNodeID()}
+
+	observeExternalAddr(db, "1.1.1.1", discovery.RandomNodeID(), "8.8.4.4")
+	observeExternalAddr(db, "2.2.2.2", discovery.NodeID{}, "8.8.4.4") // Unauthenticated peer
+
+	if db.GetSelfAddr() != "" {
+		t.Errorf("external address adopted without quorum of authenticated peers")
+	}
+
+	observeExternalAddr(db, "3.3.3.3", discovery.RandomNodeID(), "8.8.4.4")
+
+	if db.GetSelfAddr() != "8.8.4.4" {
+		t.Errorf("external address not adopted: %q", db.GetSelfAddr())
+	}
+}
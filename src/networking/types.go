@@ -4,16 +4,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
-	"log"
 	"time"
 
-	"github.com/mitsukomegumi/indo-go/src/core/types"
-	upnp "github.com/nebulouslabs/go-upnp"
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/networking/discovery"
 )
 
 // ConnectionTypes - string array representing types of connections that can be
 // made on the network, as well as how to resolve them
-var ConnectionTypes = []string{"relay", "fullchain", "statichost", "statichostfullchain", "fetchchain"}
+var ConnectionTypes = []string{"relay", "fullchain", "statichost", "statichostfullchain", "fetchchain", "syncrequest", "syncbatch", "proofrequest", "proof", "headerrequest", "headers", "accountrequest", "account", "snapshotrequest", "snapshot", "findnode", "neighbors", "ping", "pong"}
 
 // ConnectionEventTypes - preset specifications of acceptable connection event types
 var ConnectionEventTypes = []string{"closed", "accepted", "attempted", "started", "timed out"}
@@ -27,15 +26,22 @@ type Connection struct {
 
 	Data []byte `json:"data"`
 
-	Time     time.Time   `json:"inittime"`
-	TimeHash *types.Hash `json:"inithash"`
+	Time     time.Time    `json:"inittime"`
+	TimeHash *common.Hash `json:"inithash"`
 
 	Type   ConnectionType    `json:"connectiontype"`
 	Events []ConnectionEvent `json:"events"`
 
 	Extra []byte `json:"extradata"`
 
-	Hash *types.Hash `json:"connectionhash"`
+	TTL int `json:"ttl"` // Remaining number of hops data may be forwarded
+
+	ChainID common.Identifier `json:"chainid,omitempty"` // Identifier of chain connection concerns; empty for default chain
+
+	Hash *common.Hash `json:"connectionhash"`
+
+	PeerID   discovery.NodeID `json:"-"` // Set from authenticated transport on receipt; never read from data
+	PeerAddr string           `json:"-"` // Address connection was received from
 }
 
 // ConnectionEvent - string inidicating if event occurred between peers or on network
@@ -89,14 +95,3 @@ func (conn *Connection) ResolveDataWithChannel(channel chan *[]byte) error {
 
 	return nil
 }
-
-// GetGateway - get reference to current network gateway device
-func GetGateway() (*upnp.IGD, error) {
-	// connect to router
-	d, err := upnp.Discover()
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	return d, err
-}
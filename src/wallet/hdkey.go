@@ -0,0 +1,174 @@
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Keys are derived from seed following SLIP-0010 for the NIST P-256 curve, the curve used for
+// node identity keys.
+
+const (
+	// HardenedOffset - first hardened child index; hardened children cannot be derived from public keys
+	HardenedOffset uint32 = 0x80000000
+
+	// DefaultBasePath - derivation path accounts are derived under; account n uses DefaultBasePath/n
+	DefaultBasePath = "m/44'/2018'/0'/0"
+
+	masterSecret = "Nist256p1 seed"
+)
+
+// ErrInvalidPath - returned when derivation path is malformed
+var ErrInvalidPath = errors.New("invalid derivation path")
+
+// ExtendedKey - private key & chain code from which child keys are derived
+type ExtendedKey struct {
+	Key       *ecdsa.PrivateKey
+	ChainCode []byte
+	Depth     uint8
+	Index     uint32
+}
+
+// NewMasterKey - derive master extended key from wallet seed
+func NewMasterKey(seed []byte) (*ExtendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, errors.New("seed must be between 16 & 64 bytes")
+	}
+
+	data := seed
+
+	for {
+		mac := hmac.New(sha512.New, []byte(masterSecret))
+		mac.Write(data)
+		sum := mac.Sum(nil)
+
+		if key, ok := scalarKey(sum[:32]); ok {
+			return &ExtendedKey{Key: key, ChainCode: sum[32:]}, nil
+		}
+
+		data = sum // Invalid key; retry with hash of previous attempt
+	}
+}
+
+// Child - derive child extended key at specified index; indices from HardenedOffset are hardened
+func (parent *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	if parent.Depth == 255 {
+		return nil, errors.New("maximum derivation depth reached")
+	}
+
+	data := make([]byte, 0, 37)
+
+	if index >= HardenedOffset {
+		data = append(data, 0)
+		data = append(data, parent.Key.D.FillBytes(make([]byte, 32))...)
+	} else {
+		data = append(data, elliptic.MarshalCompressed(elliptic.P256(), parent.Key.X, parent.Key.Y)...)
+	}
+
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	n := elliptic.P256().Params().N
+
+	for {
+		mac := hmac.New(sha512.New, parent.ChainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+
+		tweak := new(big.Int).SetBytes(sum[:32])
+
+		if tweak.Cmp(n) < 0 {
+			d := tweak.Add(tweak, parent.Key.D)
+			d.Mod(d, n)
+
+			if key, ok := scalarKey(d.FillBytes(make([]byte, 32))); ok {
+				return &ExtendedKey{Key: key, ChainCode: sum[32:], Depth: parent.Depth + 1, Index: index}, nil
+			}
+		}
+
+		// Invalid key; retry with 0x01 || IR || index
+		data = binary.BigEndian.AppendUint32(append([]byte{1}, sum[32:]...), index)
+	}
+}
+
+// Derive - derive descendant extended key along specified path (e.g. "m/44'/2018'/0'/0/1")
+func (parent *ExtendedKey) Derive(path string) (*ExtendedKey, error) {
+	indices, err := ParsePath(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	key := parent
+
+	for _, index := range indices {
+		key, err = key.Child(index)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return key, nil
+}
+
+// ParsePath - parse derivation path into child indices; hardened indices are marked by ' or h
+func ParsePath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+
+	if parts[0] != "m" {
+		return nil, ErrInvalidPath
+	}
+
+	indices := make([]uint32, 0, len(parts)-1)
+
+	for _, part := range parts[1:] {
+		hardened := strings.HasSuffix(part, "'") || strings.HasSuffix(part, "h")
+
+		if hardened {
+			part = part[:len(part)-1]
+		}
+
+		index, err := strconv.ParseUint(part, 10, 31)
+
+		if err != nil {
+			return nil, ErrInvalidPath
+		}
+
+		if hardened {
+			index += uint64(HardenedOffset)
+		}
+
+		indices = append(indices, uint32(index))
+	}
+
+	return indices, nil
+}
+
+// AccountPath - derivation path of account with specified index under DefaultBasePath
+func AccountPath(index uint32) string {
+	return DefaultBasePath + "/" + strconv.FormatUint(uint64(index), 10)
+}
+
+// scalarKey - private key with specified scalar, if scalar is valid (non-zero & below curve order)
+func scalarKey(b []byte) (*ecdsa.PrivateKey, bool) {
+	d := new(big.Int).SetBytes(b)
+
+	if d.Sign() == 0 || d.Cmp(elliptic.P256().Params().N) >= 0 {
+		return nil, false
+	}
+
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), b)
+
+	if err != nil {
+		return nil, false
+	}
+
+	return key, true
+}
@@ -0,0 +1,200 @@
+package wallet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// StandardScryptN - scrypt CPU/memory cost used for keystores
+	StandardScryptN = 1 << 18
+
+	// StandardScryptP - scrypt parallelization used for keystores
+	StandardScryptP = 1
+
+	// LightScryptN - reduced scrypt cost for constrained devices & tests
+	LightScryptN = 1 << 12
+
+	// LightScryptP - scrypt parallelization used with LightScryptN
+	LightScryptP = 6
+
+	scryptR     = 8
+	scryptDKLen = 32
+
+	cipherName = "aes-256-gcm"
+	kdfName    = "scrypt"
+)
+
+// ErrDecrypt - returned when keystore cannot be decrypted with specified password
+var ErrDecrypt = errors.New("could not decrypt keystore; wrong password?")
+
+// KDFParams - scrypt parameters used to derive encryption key from password
+type KDFParams struct {
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+}
+
+// CryptoJSON - encrypted secret, alongside parameters needed to decrypt it with password
+type CryptoJSON struct {
+	Cipher     string    `json:"cipher"`
+	CipherText string    `json:"ciphertext"`
+	Nonce      string    `json:"nonce"`
+	KDF        string    `json:"kdf"`
+	KDFParams  KDFParams `json:"kdfparams"`
+}
+
+// newKDFParams - fresh scrypt parameters with random salt
+func newKDFParams(scryptN int, scryptP int) (KDFParams, error) {
+	salt := make([]byte, 32)
+
+	_, err := rand.Read(salt)
+
+	if err != nil {
+		return KDFParams{}, err
+	}
+
+	return KDFParams{N: scryptN, R: scryptR, P: scryptP, DKLen: scryptDKLen, Salt: hex.EncodeToString(salt)}, nil
+}
+
+// deriveKey - derive encryption key from password
+func (params KDFParams) deriveKey(password string) ([]byte, error) {
+	salt, err := hex.DecodeString(params.Salt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if params.DKLen != scryptDKLen {
+		return nil, errors.New("unsupported derived key length")
+	}
+
+	return scrypt.Key([]byte(password), salt, params.N, params.R, params.P, params.DKLen)
+}
+
+// seal - encrypt secret under key derived with specified parameters
+func seal(secret []byte, key []byte, params KDFParams) (CryptoJSON, error) {
+	aead, err := newGCM(key)
+
+	if err != nil {
+		return CryptoJSON{}, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+
+	_, err = rand.Read(nonce)
+
+	if err != nil {
+		return CryptoJSON{}, err
+	}
+
+	cipherText := aead.Seal(nil, nonce, secret, []byte(params.Salt))
+
+	return CryptoJSON{Cipher: cipherName, CipherText: hex.EncodeToString(cipherText), Nonce: hex.EncodeToString(nonce), KDF: kdfName, KDFParams: params}, nil
+}
+
+// open - decrypt secret with key derived from password, returning secret & derived key
+func (c CryptoJSON) open(password string) ([]byte, []byte, error) {
+	if c.Cipher != cipherName || c.KDF != kdfName {
+		return nil, nil, errors.New("unsupported keystore cipher " + c.Cipher + " or kdf " + c.KDF)
+	}
+
+	key, err := c.KDFParams.deriveKey(password)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	secret, err := c.openWithKey(key)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return secret, key, nil
+}
+
+// openWithKey - decrypt secret with derived key
+func (c CryptoJSON) openWithKey(key []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := hex.DecodeString(c.Nonce)
+
+	if err != nil || len(nonce) != aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+
+	cipherText, err := hex.DecodeString(c.CipherText)
+
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	secret, err := aead.Open(nil, nonce, cipherText, []byte(c.KDFParams.Salt))
+
+	if err != nil {
+		return nil, ErrDecrypt // Authentication failed: wrong password or tampered keystore
+	}
+
+	return secret, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// EncryptSecret - encrypt secret with password, returning JSON encoding of encrypted secret
+func EncryptSecret(secret []byte, password string, scryptN int, scryptP int) ([]byte, error) {
+	params, err := newKDFParams(scryptN, scryptP)
+
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := params.deriveKey(password)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := seal(secret, key, params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(sealed, "", "  ")
+}
+
+// DecryptSecret - decrypt secret from JSON encoding produced by EncryptSecret
+func DecryptSecret(b []byte, password string) ([]byte, error) {
+	sealed := CryptoJSON{}
+
+	err := json.Unmarshal(b, &sealed)
+
+	if err != nil {
+		return nil, err
+	}
+
+	secret, _, err := sealed.open(password)
+
+	return secret, err
+}
@@ -0,0 +1,149 @@
+package wallet
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"strings"
+)
+
+// Mnemonics are encoded as proquints (pronounceable quintuplets): each word of consonant-vowel-
+// consonant-vowel-consonant carries 16 bits. Entropy is followed by a 16-bit checksum (first two
+// bytes of its SHA-256 hash), so 128 bits of entropy make 9 words & 256 bits make 17 words.
+
+const (
+	consonants = "bdfghjklmnprstvz"
+	vowels     = "aiou"
+
+	// mnemonicSalt - salt prefix used when stretching mnemonic into seed
+	mnemonicSalt = "indo-mnemonic"
+
+	// seedIterations - PBKDF2 iterations used when stretching mnemonic into seed
+	seedIterations = 2048
+)
+
+var (
+	// ErrInvalidMnemonic - returned when mnemonic contains unknown words or has invalid checksum
+	ErrInvalidMnemonic = errors.New("invalid mnemonic")
+
+	// ErrEntropySize - returned when requested entropy size is not supported
+	ErrEntropySize = errors.New("entropy must be 128, 160, 192, 224 or 256 bits")
+)
+
+// NewMnemonic - generate mnemonic encoding specified number of bits of random entropy
+func NewMnemonic(bits int) (string, error) {
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return "", ErrEntropySize
+	}
+
+	entropy := make([]byte, bits/8)
+
+	_, err := rand.Read(entropy)
+
+	if err != nil {
+		return "", err
+	}
+
+	return EntropyToMnemonic(entropy)
+}
+
+// EntropyToMnemonic - encode entropy & its checksum as mnemonic words
+func EntropyToMnemonic(entropy []byte) (string, error) {
+	if len(entropy) < 16 || len(entropy) > 32 || len(entropy)%4 != 0 {
+		return "", ErrEntropySize
+	}
+
+	sum := sha256.Sum256(entropy)
+	data := append(append([]byte(nil), entropy...), sum[:2]...)
+
+	words := make([]string, len(data)/2)
+
+	for x := range words {
+		words[x] = encodeWord(uint16(data[2*x])<<8 | uint16(data[2*x+1]))
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy - decode mnemonic words, verifying checksum
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(strings.ToLower(mnemonic))
+
+	if len(words) < 9 || len(words) > 17 || len(words)%2 != 1 {
+		return nil, ErrInvalidMnemonic
+	}
+
+	data := make([]byte, 0, 2*len(words))
+
+	for _, word := range words {
+		value, err := decodeWord(word)
+
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, byte(value>>8), byte(value))
+	}
+
+	entropy, checksum := data[:len(data)-2], data[len(data)-2:]
+	sum := sha256.Sum256(entropy)
+
+	if sum[0] != checksum[0] || sum[1] != checksum[1] {
+		return nil, ErrInvalidMnemonic
+	}
+
+	return entropy, nil
+}
+
+// MnemonicToSeed - validate mnemonic & stretch it, with optional passphrase, into 64-byte wallet seed
+func MnemonicToSeed(mnemonic string, passphrase string) ([]byte, error) {
+	_, err := MnemonicToEntropy(mnemonic)
+
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := strings.Join(strings.Fields(strings.ToLower(mnemonic)), " ")
+
+	return pbkdf2.Key(sha512.New, normalized, []byte(mnemonicSalt+passphrase), seedIterations, 64)
+}
+
+// encodeWord - encode 16 bits as proquint word
+func encodeWord(value uint16) string {
+	return string([]byte{
+		consonants[value>>12&0xf],
+		vowels[value>>10&0x3],
+		consonants[value>>6&0xf],
+		vowels[value>>4&0x3],
+		consonants[value&0xf],
+	})
+}
+
+// decodeWord - decode proquint word into 16 bits
+func decodeWord(word string) (uint16, error) {
+	if len(word) != 5 {
+		return 0, ErrInvalidMnemonic
+	}
+
+	var value uint16
+
+	for x := 0; x < len(word); x++ {
+		alphabet, shift := consonants, uint(4)
+
+		if x%2 == 1 {
+			alphabet, shift = vowels, 2
+		}
+
+		index := strings.IndexByte(alphabet, word[x])
+
+		if index < 0 {
+			return 0, ErrInvalidMnemonic
+		}
+
+		value = value<<shift | uint16(index)
+	}
+
+	return value, nil
+}
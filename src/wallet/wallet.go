@@ -0,0 +1,466 @@
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+
+	"github.com/mitsukomegumi/indo-go/src/common"
+	"github.com/mitsukomegumi/indo-go/src/contracts"
+	"github.com/mitsukomegumi/indo-go/src/core/types"
+)
+
+const (
+	// WalletFile - default name of wallet keystore file
+	WalletFile = "wallet.json"
+
+	// DefaultEntropyBits - entropy of mnemonics generated for new wallets
+	DefaultEntropyBits = 128
+
+	walletVersion = 1
+)
+
+var (
+	// ErrLocked - returned when using keys of locked wallet
+	ErrLocked = errors.New("wallet locked")
+
+	// ErrUnknownAccount - returned when account is not held by wallet
+	ErrUnknownAccount = errors.New("account not held by wallet")
+
+	// ErrWalletExists - returned when creating wallet over existing keystore file
+	ErrWalletExists = errors.New("wallet already exists")
+)
+
+// Account - account held by wallet
+type Account struct {
+	Address common.Address `json:"address"`
+	Path    string         `json:"path,omitempty"` // Derivation path; empty for imported keys
+	Nonce   uint64         `json:"nonce"`          // Nonce of next transaction sent from account
+}
+
+// secrets - wallet contents encrypted in keystore
+type secrets struct {
+	Seed     []byte            `json:"seed"`
+	Imported map[string][]byte `json:"imported"` // DER-encoded keys, keyed by hex address
+}
+
+// walletJSON - keystore file format; account list is kept in plain text so nonces can be
+// tracked without unlocking
+type walletJSON struct {
+	Version  int        `json:"version"`
+	Accounts []*Account `json:"accounts"`
+	Crypto   CryptoJSON `json:"crypto"`
+}
+
+// Wallet - hierarchical deterministic wallet backed by encrypted keystore file
+type Wallet struct {
+	path string
+
+	mu       sync.Mutex
+	accounts []*Account
+	crypto   CryptoJSON
+
+	// Set while unlocked:
+	key     []byte // Keystore encryption key derived from password
+	secrets *secrets
+	master  *ExtendedKey
+}
+
+// New - create wallet from newly generated mnemonic, returning unlocked wallet & mnemonic; the
+// mnemonic is the only means of recovering the wallet without its keystore & password
+func New(path string, password string, scryptN int, scryptP int) (*Wallet, string, error) {
+	mnemonic, err := NewMnemonic(DefaultEntropyBits)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	wallet, err := Restore(path, mnemonic, "", password, scryptN, scryptP)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return wallet, mnemonic, nil
+}
+
+// Restore - create wallet from existing mnemonic & optional mnemonic passphrase, encrypting it
+// under password in new keystore at specified path; first account is derived
+func Restore(path string, mnemonic string, passphrase string, password string, scryptN int, scryptP int) (*Wallet, error) {
+	if _, err := os.Stat(path); err == nil {
+		return nil, ErrWalletExists
+	}
+
+	seed, err := MnemonicToSeed(mnemonic, passphrase)
+
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := newKDFParams(scryptN, scryptP)
+
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := params.deriveKey(password)
+
+	if err != nil {
+		return nil, err
+	}
+
+	wallet := &Wallet{path: path, crypto: CryptoJSON{KDFParams: params}, key: key}
+
+	err = wallet.setSecrets(&secrets{Seed: seed, Imported: make(map[string][]byte)})
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = wallet.Derive()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// Open - read locked wallet from keystore at specified path
+func Open(path string) (*Wallet, error) {
+	b, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	file := walletJSON{}
+
+	err = json.Unmarshal(b, &file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if file.Version != walletVersion {
+		return nil, errors.New("unsupported wallet version")
+	}
+
+	return &Wallet{path: path, accounts: file.Accounts, crypto: file.Crypto}, nil
+}
+
+// Unlock - decrypt wallet keys with password
+func (wallet *Wallet) Unlock(password string) error {
+	wallet.mu.Lock()
+	defer wallet.mu.Unlock()
+
+	b, key, err := wallet.crypto.open(password)
+
+	if err != nil {
+		return err
+	}
+
+	s := &secrets{}
+
+	err = json.Unmarshal(b, s)
+
+	if err != nil {
+		return err
+	}
+
+	master, err := NewMasterKey(s.Seed)
+
+	if err != nil {
+		return err
+	}
+
+	wallet.key, wallet.secrets, wallet.master = key, s, master
+
+	return nil
+}
+
+// Lock - discard decrypted wallet keys
+func (wallet *Wallet) Lock() {
+	wallet.mu.Lock()
+	defer wallet.mu.Unlock()
+
+	wallet.key, wallet.secrets, wallet.master = nil, nil, nil
+}
+
+// Accounts - accounts held by wallet, in order of creation
+func (wallet *Wallet) Accounts() []Account {
+	wallet.mu.Lock()
+	defer wallet.mu.Unlock()
+
+	accounts := make([]Account, len(wallet.accounts))
+
+	for x, account := range wallet.accounts {
+		accounts[x] = *account
+	}
+
+	return accounts
+}
+
+// Derive - derive next account from wallet seed
+func (wallet *Wallet) Derive() (Account, error) {
+	wallet.mu.Lock()
+	defer wallet.mu.Unlock()
+
+	if wallet.master == nil {
+		return Account{}, ErrLocked
+	}
+
+	derived := uint32(0)
+
+	for _, account := range wallet.accounts {
+		if account.Path != "" {
+			derived++
+		}
+	}
+
+	path := AccountPath(derived)
+
+	key, err := wallet.master.Derive(path)
+
+	if err != nil {
+		return Account{}, err
+	}
+
+	account := &Account{Address: types.PubkeyToAddress(&key.Key.PublicKey), Path: path}
+	wallet.accounts = append(wallet.accounts, account)
+
+	return *account, wallet.save()
+}
+
+// NonceOf - nonce of next transaction sent from account
+func (wallet *Wallet) NonceOf(addr common.Address) (uint64, error) {
+	wallet.mu.Lock()
+	defer wallet.mu.Unlock()
+
+	account := wallet.account(addr)
+
+	if account == nil {
+		return 0, ErrUnknownAccount
+	}
+
+	return account.Nonce, nil
+}
+
+// SetNonce - set nonce of next transaction sent from account (e.g. after syncing with chain)
+func (wallet *Wallet) SetNonce(addr common.Address, nonce uint64) error {
+	wallet.mu.Lock()
+	defer wallet.mu.Unlock()
+
+	account := wallet.account(addr)
+
+	if account == nil {
+		return ErrUnknownAccount
+	}
+
+	account.Nonce = nonce
+
+	return wallet.save()
+}
+
+// NewTransaction - create transaction from account with account's next nonce, signed by account's key
+func (wallet *Wallet) NewTransaction(from common.Address, to common.Address, amount types.Amount, data []byte, contract *contracts.Contract, extra []byte) (*types.Transaction, error) {
+	wallet.mu.Lock()
+	defer wallet.mu.Unlock()
+
+	account := wallet.account(from)
+
+	if account == nil {
+		return nil, ErrUnknownAccount
+	}
+
+	key, err := wallet.privateKey(account)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tx := types.NewTransaction(account.Nonce, *types.NewAccount(from), to, amount, data, contract, extra)
+
+	err = tx.SignWith(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	account.Nonce++
+
+	return tx, wallet.save()
+}
+
+// SignTx - sign transaction with key of its sending account
+func (wallet *Wallet) SignTx(tx *types.Transaction) error {
+	wallet.mu.Lock()
+	defer wallet.mu.Unlock()
+
+	account := wallet.account(tx.SendingAccount.Address)
+
+	if account == nil {
+		return ErrUnknownAccount
+	}
+
+	key, err := wallet.privateKey(account)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.SignWith(key)
+}
+
+// Export - export key of single account, encrypted under specified password
+func (wallet *Wallet) Export(addr common.Address, password string) ([]byte, error) {
+	wallet.mu.Lock()
+	defer wallet.mu.Unlock()
+
+	account := wallet.account(addr)
+
+	if account == nil {
+		return nil, ErrUnknownAccount
+	}
+
+	key, err := wallet.privateKey(account)
+
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalECPrivateKey(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return EncryptSecret(der, password, wallet.crypto.KDFParams.N, wallet.crypto.KDFParams.P)
+}
+
+// Import - import single key exported by Export, adding its account to wallet
+func (wallet *Wallet) Import(b []byte, password string) (Account, error) {
+	der, err := DecryptSecret(b, password)
+
+	if err != nil {
+		return Account{}, err
+	}
+
+	key, err := x509.ParseECPrivateKey(der)
+
+	if err != nil {
+		return Account{}, err
+	}
+
+	if key.Curve != elliptic.P256() {
+		return Account{}, errors.New("unsupported key curve")
+	}
+
+	wallet.mu.Lock()
+	defer wallet.mu.Unlock()
+
+	if wallet.secrets == nil {
+		return Account{}, ErrLocked
+	}
+
+	addr := types.PubkeyToAddress(&key.PublicKey)
+
+	if existing := wallet.account(addr); existing != nil {
+		return *existing, nil
+	}
+
+	updated := &secrets{Seed: wallet.secrets.Seed, Imported: make(map[string][]byte)}
+
+	for k, v := range wallet.secrets.Imported {
+		updated.Imported[k] = v
+	}
+
+	updated.Imported[hex.EncodeToString(addr[:])] = der
+
+	err = wallet.setSecrets(updated)
+
+	if err != nil {
+		return Account{}, err
+	}
+
+	account := &Account{Address: addr}
+	wallet.accounts = append(wallet.accounts, account)
+
+	return *account, wallet.save()
+}
+
+// account - account with specified address, if held by wallet
+func (wallet *Wallet) account(addr common.Address) *Account {
+	for _, account := range wallet.accounts {
+		if account.Address == addr {
+			return account
+		}
+	}
+	return nil
+}
+
+// privateKey - private key of account held by unlocked wallet
+func (wallet *Wallet) privateKey(account *Account) (*ecdsa.PrivateKey, error) {
+	if wallet.secrets == nil {
+		return nil, ErrLocked
+	}
+
+	if account.Path == "" {
+		der, found := wallet.secrets.Imported[hex.EncodeToString(account.Address[:])]
+
+		if !found {
+			return nil, ErrUnknownAccount
+		}
+
+		return x509.ParseECPrivateKey(der)
+	}
+
+	key, err := wallet.master.Derive(account.Path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return key.Key, nil
+}
+
+// setSecrets - encrypt & hold new wallet secrets; wallet must be unlocked
+func (wallet *Wallet) setSecrets(s *secrets) error {
+	b, err := json.Marshal(s)
+
+	if err != nil {
+		return err
+	}
+
+	sealed, err := seal(b, wallet.key, wallet.crypto.KDFParams)
+
+	if err != nil {
+		return err
+	}
+
+	master, err := NewMasterKey(s.Seed)
+
+	if err != nil {
+		return err
+	}
+
+	wallet.crypto, wallet.secrets, wallet.master = sealed, s, master
+
+	return nil
+}
+
+// save - write keystore to wallet path, readable only by current user
+func (wallet *Wallet) save() error {
+	b, err := json.MarshalIndent(walletJSON{Version: walletVersion, Accounts: wallet.accounts, Crypto: wallet.crypto}, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(wallet.path, b, 0600)
+}
@@ -0,0 +1,167 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitsukomegumi/indo-go/src/core/types"
+)
+
+func TestMnemonic(t *testing.T) {
+	entropy, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+
+	mnemonic, err := EntropyToMnemonic(entropy)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if words := strings.Fields(mnemonic); len(words) != 9 || words[0] != "babad" {
+		t.Errorf("unexpected mnemonic %q", mnemonic)
+	}
+
+	decoded, err := MnemonicToEntropy(strings.ToUpper(mnemonic))
+
+	if err != nil || hex.EncodeToString(decoded) != hex.EncodeToString(entropy) {
+		t.Errorf("mnemonic did not round trip (%v)", err)
+	}
+
+	mistyped := "dabad" + mnemonic[5:]
+
+	if _, err := MnemonicToSeed(mistyped, ""); err != ErrInvalidMnemonic {
+		t.Errorf("mistyped mnemonic accepted")
+	}
+}
+
+func TestDerivation(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+
+	master, err := NewMasterKey(seed)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// SLIP-0010 test vector 1 for nist256p1
+	if hex.EncodeToString(master.ChainCode) != "beeb672fe4621673f722f38529c07392fecaa61015c80c34f29ce8b41b3cb6ea" ||
+		hex.EncodeToString(master.Key.D.Bytes()) != "612091aaa12e22dd2abef664f8a01a82cae99ad7441b7ef8110424915c268bc2" {
+		t.Errorf("unexpected master key")
+	}
+
+	child, err := master.Derive("m/0'")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hex.EncodeToString(child.ChainCode) != "3460cea53e6a6bb5fb391eeef3237ffd8724bf0a40e94943c98b83825342ee11" ||
+		hex.EncodeToString(child.Key.D.Bytes()) != "6939694369114c67917a182c59ddb8cafc3004e63ca5d3b84403ba8613debc0c" {
+		t.Errorf("unexpected hardened child key")
+	}
+
+	if _, err := ParsePath("44'/0"); err != ErrInvalidPath {
+		t.Errorf("path without master accepted")
+	}
+}
+
+func TestWallet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wallet")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, WalletFile)
+
+	wallet, mnemonic, err := New(path, "password", LightScryptN, LightScryptP)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := wallet.Derive()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := wallet.Accounts()[0]
+
+	tx, err := wallet.NewTransaction(first.Address, second.Address, types.NewAmount(5), nil, nil, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.VerifySignature(); err != nil {
+		t.Errorf("signature not valid: %s", err.Error())
+	}
+
+	tx.Data.Amount = types.NewAmount(500)
+
+	if err := tx.VerifySignature(); err != types.ErrInvalidSignature {
+		t.Errorf("tampered transaction accepted")
+	}
+
+	if nonce, _ := wallet.NonceOf(first.Address); nonce != 1 {
+		t.Errorf("nonce not advanced: %d", nonce)
+	}
+
+	exported, err := wallet.Export(second.Address, "export")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := Restore(filepath.Join(dir, "restored.json"), mnemonic, "", "other", LightScryptN, LightScryptP)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if restored.Accounts()[0].Address != first.Address {
+		t.Errorf("restored wallet derived different account")
+	}
+
+	if _, err := restored.Import(exported, "wrong"); err != ErrDecrypt {
+		t.Errorf("expected decryption failure, got %v", err)
+	}
+
+	imported, err := restored.Import(exported, "export")
+
+	if err != nil || imported.Address != second.Address {
+		t.Fatalf("key not imported (%v)", err)
+	}
+
+	reopened, err := Open(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reopened.SignTx(tx); err != ErrLocked {
+		t.Errorf("locked wallet signed transaction")
+	}
+
+	if err := reopened.Unlock("wrong"); err != ErrDecrypt {
+		t.Errorf("wallet unlocked with wrong password")
+	}
+
+	if err := reopened.Unlock("password"); err != nil {
+		t.Fatal(err)
+	}
+
+	if accounts := reopened.Accounts(); len(accounts) != 2 || accounts[0].Nonce != 1 {
+		t.Errorf("accounts or nonces not persisted: %v", accounts)
+	}
+
+	if err := reopened.SignTx(tx); err != nil || tx.VerifySignature() != nil {
+		t.Errorf("reopened wallet could not sign (%v)", err)
+	}
+}